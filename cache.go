@@ -0,0 +1,221 @@
+package narrow
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Cache stores response body keyed by canonical request URL.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns body stored by key unless expired
+	Get(key string) ([]byte, bool)
+	// Set stores body by key for ttl
+	Set(key string, body []byte, ttl time.Duration)
+}
+
+// CacheTTL is time to live of cached responses for each kind of request, 0 disables cache for the kind
+type CacheTTL struct {
+	// Search is for api responses
+	Search time.Duration
+	// Index is for novel top page, series index and short story
+	Index time.Duration
+	// Episode is for episode page of series
+	Episode time.Duration
+}
+
+// DefaultCacheTTL is short for search, long for episode pages
+var DefaultCacheTTL = CacheTTL{Search: 5 * time.Minute, Index: 30 * time.Minute, Episode: 24 * time.Hour}
+
+// WithCache set response cache
+func WithCache(cache Cache, ttl CacheTTL) ClientOption {
+	return func(c *Client) {
+		c.cache = cache
+		c.cacheTTL = ttl
+	}
+}
+
+type bypassCacheKey struct{}
+
+// WithoutCache returns context which makes requests skip reading cache, fresh responses are still stored
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCacheKey{}, true)
+}
+
+func bypassCache(ctx context.Context) bool {
+	b, _ := ctx.Value(bypassCacheKey{}).(bool)
+	return b
+}
+
+func (ttl CacheTTL) of(ep endpoint) time.Duration {
+	switch ep {
+	case endpointAPI:
+		return ttl.Search
+	case endpointIndex:
+		return ttl.Index
+	case endpointEpisode:
+		return ttl.Episode
+	}
+	return 0
+}
+
+// cached returns body of key from cache if usable
+func (c *Client) cached(ctx context.Context, ep endpoint, key string) ([]byte, bool) {
+	if c.cache == nil || c.cacheTTL.of(ep) <= 0 || bypassCache(ctx) {
+		return nil, false
+	}
+	return c.cache.Get(key)
+}
+
+// storeCache stores body accepted by caller
+func (c *Client) storeCache(ep endpoint, key string, body []byte) {
+	if c.cache == nil {
+		return
+	}
+	if ttl := c.cacheTTL.of(ep); ttl > 0 {
+		c.cache.Set(key, body, ttl)
+	}
+}
+
+// MemoryCache is in-memory LRU Cache
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	entries    map[string]*list.Element
+	now        func() time.Time
+}
+
+type memoryCacheEntry struct {
+	key     string
+	body    []byte
+	expires time.Time
+}
+
+// NewMemoryCache returns LRU cache holding at most maxEntries responses, 0 means no limit
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		entries:    make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+// Get returns body stored by key unless expired
+func (mc *MemoryCache) Get(key string) ([]byte, bool) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	el, ok := mc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	ent := el.Value.(*memoryCacheEntry)
+	if !mc.now().Before(ent.expires) {
+		mc.ll.Remove(el)
+		delete(mc.entries, key)
+		return nil, false
+	}
+	mc.ll.MoveToFront(el)
+	return ent.body, true
+}
+
+// Set stores body by key for ttl, evicting least recently used entry if full
+func (mc *MemoryCache) Set(key string, body []byte, ttl time.Duration) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	expires := mc.now().Add(ttl)
+	if el, ok := mc.entries[key]; ok {
+		ent := el.Value.(*memoryCacheEntry)
+		ent.body, ent.expires = body, expires
+		mc.ll.MoveToFront(el)
+		return
+	}
+	mc.entries[key] = mc.ll.PushFront(&memoryCacheEntry{key: key, body: body, expires: expires})
+	if mc.maxEntries > 0 && mc.ll.Len() > mc.maxEntries {
+		oldest := mc.ll.Back()
+		mc.ll.Remove(oldest)
+		delete(mc.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
+
+// Len returns number of entries including expired ones
+func (mc *MemoryCache) Len() int {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	return mc.ll.Len()
+}
+
+// DiskCache is Cache storing each response in a file under dir
+type DiskCache struct {
+	dir string
+	now func() time.Time
+}
+
+// NewDiskCache returns cache storing files in dir, dir is created if not exists
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir, now: time.Now}, nil
+}
+
+// file layout: 8 bytes big endian expiry unix nano, then body
+const diskCacheHeaderLen = 8
+
+func (dc *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(dc.dir, name[:2], name)
+}
+
+// Get returns body stored by key unless expired
+func (dc *DiskCache) Get(key string) ([]byte, bool) {
+	p := dc.path(key)
+	data, err := ioutil.ReadFile(p)
+	if err != nil || len(data) < diskCacheHeaderLen {
+		return nil, false
+	}
+	expires := time.Unix(0, int64(binary.BigEndian.Uint64(data[:diskCacheHeaderLen])))
+	if !dc.now().Before(expires) {
+		os.Remove(p)
+		return nil, false
+	}
+	return data[diskCacheHeaderLen:], true
+}
+
+// Set stores body by key for ttl, write errors are ignored
+func (dc *DiskCache) Set(key string, body []byte, ttl time.Duration) {
+	p := dc.path(key)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(p), ".tmp-")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	var header [diskCacheHeaderLen]byte
+	binary.BigEndian.PutUint64(header[:], uint64(dc.now().Add(ttl).UnixNano()))
+	_, err = tmp.Write(header[:])
+	if err == nil {
+		_, err = tmp.Write(body)
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return
+	}
+	// rename is atomic, readers never see partial file
+	os.Rename(tmp.Name(), p)
+}
@@ -0,0 +1,127 @@
+package narrow
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (fc *fakeClock) now() time.Time { return fc.t }
+
+func TestMemoryCache(t *testing.T) {
+	clock := &fakeClock{time.Unix(1500000000, 0)}
+	mc := NewMemoryCache(2)
+	mc.now = clock.now
+
+	mc.Set("a", []byte("A"), time.Minute)
+	mc.Set("b", []byte("B"), time.Hour)
+	if got, ok := mc.Get("a"); !ok || string(got) != "A" {
+		t.Errorf("MemoryCache.Get(a) = %s, %v", got, ok)
+	}
+	// b is least recently used
+	mc.Set("c", []byte("C"), time.Hour)
+	if _, ok := mc.Get("b"); ok {
+		t.Errorf("MemoryCache.Get(b) should be evicted")
+	}
+	if mc.Len() != 2 {
+		t.Errorf("MemoryCache.Len() = %d, want 2", mc.Len())
+	}
+
+	clock.t = clock.t.Add(2 * time.Minute)
+	if _, ok := mc.Get("a"); ok {
+		t.Errorf("MemoryCache.Get(a) should be expired")
+	}
+	if got, ok := mc.Get("c"); !ok || string(got) != "C" {
+		t.Errorf("MemoryCache.Get(c) = %s, %v", got, ok)
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	clock := &fakeClock{time.Unix(1500000000, 0)}
+	dc, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDiskCache() error = %v", err)
+	}
+	dc.now = clock.now
+
+	key := NarouAPIEndPoint + "?out=json"
+	if _, ok := dc.Get(key); ok {
+		t.Errorf("DiskCache.Get() should miss")
+	}
+	dc.Set(key, []byte(`[{"allcount":1}]`), time.Minute)
+	if got, ok := dc.Get(key); !ok || string(got) != `[{"allcount":1}]` {
+		t.Errorf("DiskCache.Get() = %s, %v", got, ok)
+	}
+	clock.t = clock.t.Add(time.Minute)
+	if _, ok := dc.Get(key); ok {
+		t.Errorf("DiskCache.Get() should be expired")
+	}
+}
+
+func TestClient_Search_cache(t *testing.T) {
+	requests := 0
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		if req.URL.Query().Get("lim") == "2" {
+			return textResponse(http.StatusOK, `[]`), nil
+		}
+		return textResponse(http.StatusOK, fmt.Sprintf(`[{"allcount":%d}]`, requests)), nil
+	})
+	cache := NewMemoryCache(0)
+	c := &Client{httpClient: &http.Client{Transport: transport}}
+	WithCache(cache, CacheTTL{Search: time.Minute})(c)
+
+	ctx := context.Background()
+	params := NewSearchParams()
+	first, _ := c.Search(ctx, params)
+	second, _ := c.Search(ctx, params)
+	if requests != 1 || !reflect.DeepEqual(first, second) {
+		t.Errorf("Client.Search() should use cache, requests = %d, %+v, %+v", requests, first, second)
+	}
+
+	fresh, _ := c.Search(WithoutCache(ctx), params)
+	if requests != 2 || fresh.AllCount != 2 {
+		t.Errorf("Client.Search(WithoutCache) should bypass cache, requests = %d, %+v", requests, fresh)
+	}
+	// fresh result replaces cached one
+	if third, _ := c.Search(ctx, params); requests != 2 || third.AllCount != 2 {
+		t.Errorf("Client.Search() should use refreshed cache, requests = %d, %+v", requests, third)
+	}
+
+	// error response is not cached
+	params.SetLimit(2)
+	c.Search(ctx, params)
+	c.Search(ctx, params)
+	if requests != 4 {
+		t.Errorf("Client.Search() should not cache error, requests = %d", requests)
+	}
+}
+
+func TestClient_Search_cacheExpires(t *testing.T) {
+	requests := 0
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return textResponse(http.StatusOK, fmt.Sprintf(`[{"allcount":%d}]`, requests)), nil
+	})
+	clock := &fakeClock{t: time.Unix(1500000000, 0)}
+	cache := NewMemoryCache(0)
+	cache.now = clock.now
+	c := &Client{httpClient: &http.Client{Transport: transport}}
+	WithCache(cache, CacheTTL{Search: time.Minute})(c)
+
+	// reading cache every 50s does not extend its expiry
+	ctx := context.Background()
+	params := NewSearchParams()
+	for i := 0; i < 5; i++ {
+		c.Search(ctx, params)
+		clock.t = clock.t.Add(50 * time.Second)
+	}
+	if requests != 3 {
+		t.Errorf("Client.Search() 5 times in 250s with 1m ttl, requests = %d, want 3", requests)
+	}
+}
@@ -1,20 +1,232 @@
 package narrow
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
 // A Client fetch data from novel api
 type Client struct {
 	httpClient *http.Client
+	userAgent  string
+
+	apiBaseURL        string
+	r18APIBaseURL     string
+	rankingBaseURL    string
+	rankinBaseURL     string
+	userAPIBaseURL    string
+	contentBaseURL    string
+	r18ContentBaseURL string
+
+	limiter  *rateLimiter
+	retry    RetryPolicy
+	cache    Cache
+	cacheTTL CacheTTL
+	logger   *slog.Logger
+
+	fetchConcurrency int
+
+	// clock is replaced in tests, nil means time.Now
+	clock func() time.Time
 }
 
 var userAgent = fmt.Sprintf("go-narrow/%s", Version)
 
+// default content hosts
+const (
+	NarouContentBaseURL    = "https://ncode.syosetu.com/"
+	NarouR18ContentBaseURL = "https://novel18.syosetu.com/"
+)
+
+// ClientOption configures Client
+type ClientOption func(*Client)
+
+// WithHTTPClient use hc for every request
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithUserAgent set user-agent header, such as `mycrawler/1.0 (+mailto:me@example.com)`
+func WithUserAgent(ua string) ClientOption {
+	return func(c *Client) { c.userAgent = ua }
+}
+
+// WithAPIBaseURL replace NarouAPIEndPoint with base
+func WithAPIBaseURL(base string) ClientOption {
+	return func(c *Client) { c.apiBaseURL = base }
+}
+
+// WithR18APIBaseURL replace NarouR18APIEndPoint with base
+func WithR18APIBaseURL(base string) ClientOption {
+	return func(c *Client) { c.r18APIBaseURL = base }
+}
+
+// WithRankingBaseURL replace NarouRankingAPIEndPoint with base
+func WithRankingBaseURL(base string) ClientOption {
+	return func(c *Client) { c.rankingBaseURL = base }
+}
+
+// WithRankingHistoryBaseURL replace NarouRankingHistoryAPIEndPoint with base
+func WithRankingHistoryBaseURL(base string) ClientOption {
+	return func(c *Client) { c.rankinBaseURL = base }
+}
+
+// WithUserAPIBaseURL replace NarouUserAPIEndPoint with base
+func WithUserAPIBaseURL(base string) ClientOption {
+	return func(c *Client) { c.userAPIBaseURL = base }
+}
+
+// WithContentBaseURL replace NarouContentBaseURL (`https://ncode.syosetu.com/`) with base
+func WithContentBaseURL(base string) ClientOption {
+	return func(c *Client) { c.contentBaseURL = base }
+}
+
+// WithR18ContentBaseURL replace NarouR18ContentBaseURL (`https://novel18.syosetu.com/`) with base
+func WithR18ContentBaseURL(base string) ClientOption {
+	return func(c *Client) { c.r18ContentBaseURL = base }
+}
+
+// WithLogger set logger for parse and fetch failures, output is discarded by default
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(c *Client) { c.logger = logger }
+}
+
+// WithFetchConcurrency set number of pages Fetch downloads in parallel, default is 1.
+// FetchParams.Concurrency overrides it, requests are still throttled by content rate limit
+func WithFetchConcurrency(n int) ClientOption {
+	return func(c *Client) { c.fetchConcurrency = n }
+}
+
 // NewClient returns new novel api client
-func NewClient() *Client {
-	return &Client{
-		httpClient: &http.Client{},
+func NewClient(opts ...ClientOption) *Client {
+	c := &Client{
+		httpClient:        &http.Client{},
+		userAgent:         userAgent,
+		apiBaseURL:        NarouAPIEndPoint,
+		r18APIBaseURL:     NarouR18APIEndPoint,
+		rankingBaseURL:    NarouRankingAPIEndPoint,
+		rankinBaseURL:     NarouRankingHistoryAPIEndPoint,
+		userAPIBaseURL:    NarouUserAPIEndPoint,
+		contentBaseURL:    NarouContentBaseURL,
+		r18ContentBaseURL: NarouR18ContentBaseURL,
+		limiter:           newRateLimiter(),
+		retry:             DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func (c *Client) log() *slog.Logger {
+	if c.logger == nil {
+		return discardLogger
+	}
+	return c.logger
+}
+
+func (c *Client) now() time.Time {
+	if c.clock == nil {
+		return time.Now()
+	}
+	return c.clock()
+}
+
+// rebaseAPIURL replaces official endpoint of u with configured base URL
+func (c *Client) rebaseAPIURL(u *url.URL) (*url.URL, error) {
+	bases := [][2]string{
+		{NarouAPIEndPoint, c.apiBaseURL},
+		{NarouR18APIEndPoint, c.r18APIBaseURL},
+		{NarouRankingAPIEndPoint, c.rankingBaseURL},
+		{NarouRankingHistoryAPIEndPoint, c.rankinBaseURL},
+		{NarouUserAPIEndPoint, c.userAPIBaseURL},
+	}
+	for _, b := range bases {
+		endPoint, base := b[0], b[1]
+		if base == "" || base == endPoint {
+			continue
+		}
+		if !strings.HasPrefix(u.Scheme+"://"+u.Host+u.Path, endPoint) {
+			continue
+		}
+		rebased, err := url.Parse(base)
+		if err != nil {
+			return nil, err
+		}
+		rebased.RawQuery = u.RawQuery
+		return rebased, nil
+	}
+	return u, nil
+}
+
+// contentBase returns base URL of reader pages for site
+func (c *Client) contentBase(site FetchSite) string {
+	if site != FetchSiteNarou {
+		if c.r18ContentBaseURL == "" {
+			return NarouR18ContentBaseURL
+		}
+		return c.r18ContentBaseURL
+	}
+	if c.contentBaseURL == "" {
+		return NarouContentBaseURL
+	}
+	return c.contentBaseURL
+}
+
+// get sends GET request with user-agent, waiting for rate limit of ep and retrying transient failure
+func (c *Client) get(ctx context.Context, ep endpoint, u *url.URL) (*http.Response, error) {
+	ua := c.userAgent
+	if ua == "" {
+		ua = userAgent
+	}
+
+	return c.doWithRetry(ctx, u.String(), func() (*http.Response, error) {
+		if err := c.limiter.wait(ctx, ep, u.Host); err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequest("GET", u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Add("user-agent", ua)
+		if sendOver18(ctx) {
+			req.AddCookie(&http.Cookie{Name: "over18", Value: "yes"})
+		}
+
+		req = req.WithContext(ctx)
+
+		return c.httpClient.Do(req)
+	})
+}
+
+// getBody returns status and body of u from cache or network, fromCache reports cache hit.
+// caller should storeCache body from network after accepting it, storing cached body again would extend its expiry
+func (c *Client) getBody(ctx context.Context, ep endpoint, u *url.URL) (status int, body []byte, fromCache bool, err error) {
+	key := u.String()
+	if body, ok := c.cached(ctx, ep, key); ok {
+		return http.StatusOK, body, true, nil
+	}
+
+	res, err := c.get(ctx, ep, u)
+	if err != nil {
+		return 0, nil, false, err
+	}
+	defer res.Body.Close()
+
+	body, err = ioutil.ReadAll(res.Body)
+	if err != nil {
+		return 0, nil, false, err
 	}
+	return res.StatusCode, body, false, nil
 }
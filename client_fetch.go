@@ -1,142 +1,299 @@
 package narrow
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
-	"net/http/cookiejar"
+	"log/slog"
 	"net/url"
-	"os"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
 )
 
-// Fetch download novel contents
+// Fetch download novel contents.
+// When some pages fail, Fetch returns partial result and joined *PageError,
+// failed pages have PageStatusFailed and can be fetched again by RefetchFailed
 func (c *Client) Fetch(ctx context.Context, params *FetchParams) (*FetchResult, error) {
-	contentURL, err := params.toContentURL()
+	contentURL, err := params.toContentURL(c.contentBase(params.Site))
 	if err != nil {
 		return nil, err
 	}
+	ctx = allowOver18(ctx, params)
 
-	if params.Site != FetchSiteNarou && params.AllowOver18 {
-		if c.httpClient.Jar == nil {
-			jar, err := cookiejar.New(nil)
-			if err != nil {
-				return nil, err
-			}
-			c.httpClient.Jar = jar
+	result, next, err := c.fetchIndex(ctx, params, contentURL)
+	if err != nil {
+		return nil, err
+	}
+	if result.NovelType == 1 && next != "" {
+		if err := c.fetchIndexPages(ctx, params, result, contentURL, next); err != nil {
+			return nil, err
 		}
+	}
+	result.Site = params.Site
+	result.NCode = params.NCode
+	if result.NovelType == 1 {
+		result.Chapters = indexChapters(result.Pages)
+		if params.Pages != nil {
+			return result, c.fetchPages(ctx, result, params, params.Pages.PageNumbers(result.Pages))
+		} else if params.WithContent {
+			return result, c.fetchAllPageContent(ctx, result, params)
+		} else if params.Page > 0 {
+			return result, c.fetchSinglePageContent(ctx, result, params)
+		}
+	}
+
+	return result, nil
+}
 
-		over18 := http.Cookie{Name: "over18", Value: "yes", Expires: time.Now().AddDate(1, 0, 0), Domain: ".syosetu.com"}
-		c.httpClient.Jar.SetCookies(contentURL, []*http.Cookie{&over18})
+// fetchIndex downloads and parses one index page, returns href of next index page if paginated
+func (c *Client) fetchIndex(ctx context.Context, params *FetchParams, u *url.URL) (*FetchResult, string, error) {
+	status, body, fromCache, err := c.getBody(ctx, endpointIndex, u)
+	if err != nil {
+		return nil, "", err
+	}
+	if status < 200 || status >= 300 {
+		return nil, "", fmt.Errorf("fetch index %s: status %d", u, status)
 	}
 
-	req, err := http.NewRequest("GET", contentURL.String(), nil)
+	logger := c.log().With("ncode", params.NCode, "url", u.String())
+	result, next, err := parseFetchedContent(bytes.NewReader(body), logger)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
+	if !fromCache {
+		c.storeCache(endpointIndex, u.String(), body)
+	}
+	return result, next, nil
+}
 
-	req.Header.Add("user-agent", userAgent)
+// maxIndexPages stops following broken pager links
+const maxIndexPages = 1000
 
-	req = req.WithContext(ctx)
+// fetchIndexPages follows `?p=N` index pages from next and appends their episodes to result
+func (c *Client) fetchIndexPages(ctx context.Context, params *FetchParams, result *FetchResult, contentURL *url.URL, next string) error {
+	visited := map[string]bool{contentURL.String(): true}
+	for n := 1; next != "" && n < maxIndexPages; n++ {
+		ref, err := url.Parse(next)
+		if err != nil {
+			return err
+		}
+		u := contentURL.ResolveReference(ref)
+		if visited[u.String()] {
+			break
+		}
+		visited[u.String()] = true
 
-	res, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
+		var part *FetchResult
+		part, next, err = c.fetchIndex(ctx, params, u)
+		if err != nil {
+			return err
+		}
+		result.appendIndex(part)
 	}
-	defer res.Body.Close()
+	return nil
+}
 
-	result, err := parseFetchedContent(res.Body)
-	if err != nil {
-		return nil, err
+// appendIndex appends episodes of following index page, chapter continued from previous page is inherited.
+// a heading repeated on the following page has same title, indexChapters merges it by value
+func (result *FetchResult) appendIndex(part *FetchResult) {
+	var chapter *string
+	if len(result.Pages) > 0 {
+		chapter = result.Pages[len(result.Pages)-1].ChapterTitle
 	}
-	result.Site = params.Site
-	result.NCode = params.NCode
-	if result.NovelType == 1 {
-		err = nil
-		if params.WithContent {
-			err = c.fetchAllPageContent(ctx, result, params)
-		} else if params.Page > 0 {
-			err = c.fetchSinglePageContent(ctx, result, params)
+	offset := len(result.Pages)
+	for _, page := range part.Pages {
+		if page.ChapterTitle == nil {
+			page.ChapterTitle = chapter
 		}
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "fetch page content failed. %v", err)
-			return result, nil
+		page.PageNumber += offset
+		result.Pages = append(result.Pages, page)
+	}
+	result.PageCount = len(result.Pages)
+}
+
+// indexChapters groups consecutive pages having same ChapterTitle
+func indexChapters(pages []FetchPage) []Chapter {
+	var chapters []Chapter
+	for _, page := range pages {
+		if page.ChapterTitle == nil {
+			continue
 		}
+		if len(chapters) == 0 || chapters[len(chapters)-1].Title != *page.ChapterTitle {
+			chapters = append(chapters, Chapter{Title: *page.ChapterTitle})
+		}
+		last := &chapters[len(chapters)-1]
+		last.PageNumbers = append(last.PageNumbers, page.PageNumber)
 	}
+	return chapters
+}
 
-	return result, nil
+// RefetchFailed downloads again only pages of result which have PageStatusFailed
+func (c *Client) RefetchFailed(ctx context.Context, params *FetchParams, result *FetchResult) error {
+	failed := result.FailedPages()
+	if len(failed) == 0 {
+		return nil
+	}
+	ctx = allowOver18(ctx, params)
+	return c.fetchPages(ctx, result, params, failed)
 }
 
-func (c *Client) fetchAllPageContent(ctx context.Context, result *FetchResult, params *FetchParams) error {
-	for i := 1; i <= result.PageCount; i++ {
-		page, err := c.fetchPageContent(ctx, params, i)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "fetch page %d content failed, %v", i, err)
-			return err
+// FailedPages returns page numbers which have PageStatusFailed
+func (result *FetchResult) FailedPages() []int {
+	var pageNos []int
+	for i, page := range result.Pages {
+		if page.Status == PageStatusFailed {
+			pageNos = append(pageNos, i+1)
 		}
-		// Pages[i].PublishDate, LastUpdateDate is already set
-		page.PublishDate = result.Pages[i-1].PublishDate
-		page.LastUpdateDate = result.Pages[i-1].LastUpdateDate
-		result.Pages[i-1] = *page
 	}
-	return nil
+	return pageNos
+}
+
+type over18Key struct{}
+
+// allowOver18 returns context which makes requests send over18 cookie when params allows R18 site,
+// the cookie is added per request so http.Client of WithHTTPClient is left untouched
+func allowOver18(ctx context.Context, params *FetchParams) context.Context {
+	if params.Site == FetchSiteNarou || !params.AllowOver18 {
+		return ctx
+	}
+	return context.WithValue(ctx, over18Key{}, true)
+}
+
+func sendOver18(ctx context.Context) bool {
+	b, _ := ctx.Value(over18Key{}).(bool)
+	return b
+}
+
+func (c *Client) fetchAllPageContent(ctx context.Context, result *FetchResult, params *FetchParams) error {
+	pageNos := make([]int, result.PageCount)
+	for i := range pageNos {
+		pageNos[i] = i + 1
+	}
+	return c.fetchPages(ctx, result, params, pageNos)
 }
 
 func (c *Client) fetchSinglePageContent(ctx context.Context, result *FetchResult, params *FetchParams) error {
 	if params.Page > result.PageCount {
 		// do nothing
-		fmt.Fprintf(os.Stderr, "specified page %d greater than fetched index %d", params.Page, result.PageCount)
+		c.log().Warn("specified page greater than fetched index",
+			"ncode", params.NCode, "page", params.Page, "page_count", result.PageCount)
 		return nil
 	}
-	pageNo := params.Page
+	return c.fetchPages(ctx, result, params, []int{params.Page})
+}
+
+// fetchPages downloads each page into result.Pages, failure does not stop others.
+// pages not started before ctx is done are marked failed with ctx error
+func (c *Client) fetchPages(ctx context.Context, result *FetchResult, params *FetchParams, pageNos []int) error {
+	errs := make([]error, len(pageNos))
+	workers := c.concurrency(params)
+	if workers > len(pageNos) {
+		workers = len(pageNos)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				// each worker writes distinct result.Pages element
+				errs[i] = c.fetchPageInto(ctx, result, params, pageNos[i])
+			}
+		}()
+	}
+	next := 0
+feed:
+	for ; next < len(pageNos); next++ {
+		select {
+		case jobs <- next:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i := next; i < len(pageNos); i++ {
+		errs[i] = c.cancelPage(ctx, result, params, pageNos[i])
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Client) concurrency(params *FetchParams) int {
+	if params.Concurrency > 0 {
+		return params.Concurrency
+	}
+	if c.fetchConcurrency > 0 {
+		return c.fetchConcurrency
+	}
+	return 1
+}
+
+func (c *Client) cancelPage(ctx context.Context, result *FetchResult, params *FetchParams, pageNo int) error {
+	u, err := params.toPageURL(c.contentBase(params.Site), pageNo)
+	if err != nil {
+		return err
+	}
+	err = &PageError{NCode: params.NCode, PageNumber: pageNo, URL: u.String(), Err: ctx.Err()}
+	result.Pages[pageNo-1].Status = PageStatusFailed
+	result.Pages[pageNo-1].Err = err
+	return err
+}
+
+func (c *Client) fetchPageInto(ctx context.Context, result *FetchResult, params *FetchParams, pageNo int) error {
 	page, err := c.fetchPageContent(ctx, params, pageNo)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "fetch page %d failed %s", pageNo, err)
+		result.Pages[pageNo-1].Status = PageStatusFailed
+		result.Pages[pageNo-1].Err = err
 		return err
 	}
 
 	// Pages[i].PublishDate, LastUpdateDate is already set
 	page.PublishDate = result.Pages[pageNo-1].PublishDate
 	page.LastUpdateDate = result.Pages[pageNo-1].LastUpdateDate
+	if page.ChapterTitle == nil {
+		page.ChapterTitle = result.Pages[pageNo-1].ChapterTitle
+	}
+	page.PageNumber = pageNo
+	page.Status = PageStatusFetched
 	result.Pages[pageNo-1] = *page
-
 	return nil
 }
 
 func (c *Client) fetchPageContent(ctx context.Context, params *FetchParams, pageNo int) (*FetchPage, error) {
-	u, err := params.toContentURL()
+	u, err := params.toPageURL(c.contentBase(params.Site), pageNo)
 	if err != nil {
 		return nil, err
 	}
-	u.Path = fmt.Sprintf("%s%d/", u.Path, pageNo)
-
-	req, err := http.NewRequest("GET", u.String(), nil)
-	if err != nil {
-		return nil, err
+	logger := c.log().With("ncode", params.NCode, "page", pageNo, "url", u.String())
+	pageError := func(err error) error {
+		logger.Warn("fetch page failed", "error", err)
+		return &PageError{NCode: params.NCode, PageNumber: pageNo, URL: u.String(), Err: err}
 	}
 
-	req.Header.Add("user-agent", userAgent)
-
-	req = req.WithContext(ctx)
-
-	res, err := c.httpClient.Do(req)
+	status, body, fromCache, err := c.getBody(ctx, endpointEpisode, u)
 	if err != nil {
-		return nil, err
+		return nil, pageError(err)
+	}
+	if status < 200 || status >= 300 {
+		// error page would be parsed into empty page
+		return nil, pageError(fmt.Errorf("status %d", status))
 	}
-	defer res.Body.Close()
 
-	doc, err := goquery.NewDocumentFromReader(res.Body)
+	page, err := parseFetchedPage(bytes.NewReader(body), logger)
 	if err != nil {
-		return nil, err
+		return nil, pageError(err)
 	}
-	page, err := parseContentPage(doc)
-	if err != nil {
-		return nil, err
+	if !fromCache {
+		c.storeCache(endpointEpisode, u.String(), body)
 	}
 
 	return page, nil
@@ -148,78 +305,54 @@ func NewFetchParams() *FetchParams {
 	return params
 }
 
-func (params *FetchParams) toContentURL() (*url.URL, error) {
-	subDomain := "ncode"
-	if params.Site != FetchSiteNarou {
-		subDomain = "novel18"
+// toContentURL returns `<base>/<ncode>/`, base is such as `https://ncode.syosetu.com/`
+func (params *FetchParams) toContentURL(base string) (*url.URL, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, err
 	}
+	u.Path = fmt.Sprintf("%s/%s/", strings.TrimSuffix(u.Path, "/"), params.NCode)
+	return u, nil
+}
 
-	u, err := url.Parse(fmt.Sprintf("https://%s.syosetu.com/%s/", subDomain, params.NCode))
-	return u, err
+// toPageURL returns `<base>/<ncode>/<pageNo>/`
+func (params *FetchParams) toPageURL(base string, pageNo int) (*url.URL, error) {
+	u, err := params.toContentURL(base)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = fmt.Sprintf("%s%d/", u.Path, pageNo)
+	return u, nil
 }
 
-func parseFetchedContent(r io.Reader) (*FetchResult, error) {
+// parseFetchedContent parses index or short story page, next is href of next index page or empty
+func parseFetchedContent(r io.Reader, logger *slog.Logger) (result *FetchResult, next string, err error) {
 	doc, err := goquery.NewDocumentFromReader(r)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	abst := doc.Find("#novel_ex")
-	if abst.Size() == 0 {
-		res, err := parseShortContentPage(doc)
-		return res, err
+	l := detectLayout(doc)
+	if !l.isSeriesIndex(doc) {
+		result, err = l.parseShortContent(doc, logger)
+		return result, "", err
 	}
-
-	res, err := parseSeriesIndexPage(doc)
-	return res, err
+	result, err = l.parseSeriesIndex(doc, logger)
+	if err != nil {
+		return nil, "", err
+	}
+	return result, l.nextIndexPage(doc), nil
 }
 
-func parseSeriesIndexPage(doc *goquery.Document) (*FetchResult, error) {
-	res := &FetchResult{}
-	res.Title = strings.TrimSpace(doc.Find("title").First().Text())
-	res.WriterName = strings.TrimSpace(doc.Find("div.novel_writername").First().Text())
-	res.NovelType = 1
-	res.Abstruct = doc.Find("#novel_ex").Text()
-	pages := doc.Find("div.index_box > dl.novel_sublist2")
-	res.PageCount = pages.Size()
-	res.Pages = make([]FetchPage, res.PageCount)
-	pages.Each(func(i int, s *goquery.Selection) {
-		res.Pages[i] = FetchPage{}
-		subTitle := s.Find("dd.subtitle a").First().Text()
-		res.Pages[i].SubTitle = strings.TrimSpace(subTitle)
-
-		pubDateStr := s.Find("dt.long_update").First().Text()
-		pubDateStr = kaiRe.ReplaceAllString(pubDateStr, "")
-		pubDateStr = strings.TrimSpace(pubDateStr)
-		pubDate, err := asJST(pubDateStr)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "pubdate parse failed %v\n", err)
-		} else {
-			res.Pages[i].PublishDate = pubDate
-		}
-
-		kai := s.Find("dt.long_update span").First()
-		if kai.Size() == 1 {
-			if upd, ok := kai.Attr("title"); ok {
-				upd = kaikouRe.ReplaceAllString(upd, "")
-				upd = strings.TrimSpace(upd)
-				updateDate, err := asJST(upd)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "update date parse failed %v\n", err)
-				} else {
-					res.Pages[i].LastUpdateDate = &updateDate
-				}
-			}
-		}
-	})
-	return res, nil
+func parseFetchedPage(r io.Reader, logger *slog.Logger) (*FetchPage, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return detectLayout(doc).parseContent(doc, logger)
 }
 
 func asJST(str string) (time.Time, error) {
-	loc, err := time.LoadLocation("Asia/Tokyo")
-	if err != nil {
-		return time.Time{}, err
-	}
-	t, err := time.ParseInLocation(novelUpdateTimeFormat, str, loc)
+	t, err := time.ParseInLocation(novelUpdateTimeFormat, str, jst)
 	if err != nil {
 		return time.Time{}, err
 	}
@@ -230,54 +363,3 @@ const novelUpdateTimeFormat = "2006/01/02 15:04"
 
 var kaiRe = regexp.MustCompile(`\s*（改）\s*`)
 var kaikouRe = regexp.MustCompile(`\s*改稿\s*`)
-
-func parseShortContentPage(doc *goquery.Document) (*FetchResult, error) {
-	res := &FetchResult{}
-	res.Title = doc.Find("title").First().Text()
-	res.WriterName = strings.TrimSpace(doc.Find("div.novel_writername").First().Text())
-	res.NovelType = 2
-	res.PageCount = 1
-	res.Pages = make([]FetchPage, 1)
-	page, err := parseContentPage(doc)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "parse content page error:%s", err)
-	}
-	res.Pages[0] = *page
-	return res, nil
-}
-
-func parseContentLines(doc *goquery.Document, selector string) []ContentLine {
-	honbun := doc.Find(selector).First()
-	if honbun.Size() == 0 {
-		return nil
-	}
-	lines := honbun.Find("p")
-	content := make([]ContentLine, lines.Size())
-	lines.Each(func(i int, s *goquery.Selection) {
-		raw, err := goquery.OuterHtml(s) // want p#L
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "raw line error:%s", err)
-		}
-		content[i] = ContentLine{RawLine: raw}
-	})
-	return content
-}
-
-func parseContentPage(doc *goquery.Document) (*FetchPage, error) {
-	page := &FetchPage{}
-	page.SubTitle = strings.TrimSpace(doc.Find("div.novel_subtitle").First().Text())
-	chapter := doc.Find(".chapter_title")
-	if chapter.Size() != 0 {
-		title := strings.TrimSpace(chapter.First().Text())
-		page.ChapterTitle = &title
-	}
-	page.Preface = parseContentLines(doc, "#novel_p")
-	page.Lines = parseContentLines(doc, "#novel_honbun")
-	page.Afterword = parseContentLines(doc, "#novel_a")
-	raw, err := doc.Find("#novel_color").First().Html()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "raw content html error:%s", err)
-	}
-	page.rawHTML = raw
-	return page, nil
-}
@@ -0,0 +1,383 @@
+package narrow
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func readTestData(t *testing.T, name string) string {
+	t.Helper()
+	b, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read testdata %s: %v", name, err)
+	}
+	return string(b)
+}
+
+// fakeContentSite serves pages by request path, unknown path is 404
+func fakeContentSite(pages map[string]string, requests *[]string) roundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		if requests != nil {
+			*requests = append(*requests, req.URL.Path)
+		}
+		body, ok := pages[req.URL.Path]
+		if !ok {
+			return textResponse(http.StatusNotFound, "not found"), nil
+		}
+		return textResponse(http.StatusOK, body), nil
+	}
+}
+
+func TestClient_Fetch(t *testing.T) {
+	index := readTestData(t, "legacy_index.html")
+	episode := readTestData(t, "legacy_episode.html")
+	c := &Client{httpClient: &http.Client{Transport: fakeContentSite(map[string]string{
+		"/n0000aa/":   index,
+		"/n0000aa/1/": episode,
+		"/n0000aa/2/": episode,
+		"/n0000aa/3/": episode,
+	}, nil)}}
+
+	res, err := c.Fetch(context.Background(), &FetchParams{NCode: "n0000aa", WithContent: true})
+	if err != nil {
+		t.Fatalf("Client.Fetch() error = %v", err)
+	}
+	if res.Title != "徒然草" || res.NovelType != 1 || res.PageCount != 3 || len(res.Pages) != 3 {
+		t.Errorf("Client.Fetch() = %+v", res)
+	}
+	page := res.Pages[0]
+	if page.SubTitle != "つれづれなるままに" || len(page.Lines) != 3 || len(page.Preface) != 1 || len(page.Afterword) != 1 {
+		t.Errorf("Client.Fetch() page = %+v", page)
+	}
+	wantChapters := []Chapter{{Title: "序段", PageNumbers: []int{1}}, {Title: "上", PageNumbers: []int{2, 3}}}
+	if !reflect.DeepEqual(res.Chapters, wantChapters) {
+		t.Errorf("Client.Fetch() chapters = %+v, want %+v", res.Chapters, wantChapters)
+	}
+	if line := page.Lines[0]; line.Number != 1 || line.Text != "　つれづれなるままに、日くらし、硯にむかひて、" || len(line.Segments) != 3 {
+		t.Errorf("Client.Fetch() line = %+v", line)
+	}
+	if !page.PublishDate.Equal(*jstDate(2019, 5, 6, 18, 39, 0, 0)) || page.LastUpdateDate == nil || !page.LastUpdateDate.Equal(*jstDate(2019, 5, 7, 10, 0, 0, 0)) {
+		t.Errorf("Client.Fetch() page dates = %v, %v", page.PublishDate, page.LastUpdateDate)
+	}
+}
+
+func TestClient_Fetch_logger(t *testing.T) {
+	index := strings.Replace(readTestData(t, "legacy_index.html"), "2019/05/07 18:39", "2019-05-07", 1)
+	var buf bytes.Buffer
+	site := fakeContentSite(map[string]string{"/n0000aa/": index}, nil)
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/n0000aa/2/" {
+			return nil, fmt.Errorf("broken: %w", io.ErrUnexpectedEOF)
+		}
+		return site(req)
+	})
+	c := NewClient(
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithContentRateLimit(RateLimit{}),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2}),
+		WithLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+	)
+
+	if _, err := c.Fetch(context.Background(), &FetchParams{NCode: "n0000aa", Page: 2}); err == nil {
+		t.Fatalf("Client.Fetch() error = nil, want page error")
+	}
+	logs := buf.String()
+	for _, want := range []string{
+		`"msg":"pubdate parse failed"`, `"ncode":"n0000aa"`, `"page":2`, `"value":"2019-05-07"`,
+		`"msg":"fetch page failed"`, `"url":"https://ncode.syosetu.com/n0000aa/2/"`,
+	} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("Client.Fetch() logs = %s, want %s", logs, want)
+		}
+	}
+	// each failure is logged once, retries are reported by RetryPolicy.OnRetry
+	if n := strings.Count(logs, "broken"); n != 1 {
+		t.Errorf("Client.Fetch() logs failure %d times, want once: %s", n, logs)
+	}
+}
+
+func TestClient_Fetch_partial(t *testing.T) {
+	index := readTestData(t, "legacy_index.html")
+	episode := readTestData(t, "legacy_episode.html")
+	var requests []string
+	site := fakeContentSite(map[string]string{
+		"/n0000aa/":   index,
+		"/n0000aa/1/": episode,
+		"/n0000aa/2/": episode,
+		"/n0000aa/3/": episode,
+	}, &requests)
+	broken := map[string]bool{"/n0000aa/2/": true}
+	c := &Client{httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if broken[req.URL.Path] {
+			return nil, errors.New("broken")
+		}
+		return site(req)
+	})}}
+
+	params := &FetchParams{NCode: "n0000aa", WithContent: true}
+	res, err := c.Fetch(context.Background(), params)
+	var pe *PageError
+	if !errors.As(err, &pe) || pe.PageNumber != 2 || pe.URL != "https://ncode.syosetu.com/n0000aa/2/" {
+		t.Fatalf("Client.Fetch() error = %v, want *PageError of page 2", err)
+	}
+	if res == nil || len(res.Pages) != 3 {
+		t.Fatalf("Client.Fetch() = %+v, want partial result", res)
+	}
+	wantStatus := []PageStatus{PageStatusFetched, PageStatusFailed, PageStatusFetched}
+	for i, page := range res.Pages {
+		if page.Status != wantStatus[i] || page.PageNumber != i+1 {
+			t.Errorf("Client.Fetch() page %d = %v %d, want %v", i+1, page.Status, page.PageNumber, wantStatus[i])
+		}
+	}
+	// failed page keeps index information
+	if page := res.Pages[1]; page.Err == nil || page.SubTitle != "いでや、この世に生れては" || page.PublishDate.IsZero() {
+		t.Errorf("Client.Fetch() failed page = %+v", page)
+	}
+	if got := res.FailedPages(); len(got) != 1 || got[0] != 2 {
+		t.Errorf("FetchResult.FailedPages() = %v, want [2]", got)
+	}
+
+	delete(broken, "/n0000aa/2/")
+	requests = nil
+	if err := c.RefetchFailed(context.Background(), params, res); err != nil {
+		t.Fatalf("Client.RefetchFailed() error = %v", err)
+	}
+	if len(requests) != 1 || requests[0] != "/n0000aa/2/" {
+		t.Errorf("Client.RefetchFailed() requests = %v, want only page 2", requests)
+	}
+	if page := res.Pages[1]; page.Status != PageStatusFetched || page.Err != nil || len(page.Lines) != 3 || page.PublishDate.IsZero() {
+		t.Errorf("Client.RefetchFailed() page = %+v", page)
+	}
+	if got := res.FailedPages(); len(got) != 0 {
+		t.Errorf("FetchResult.FailedPages() = %v, want none", got)
+	}
+}
+
+func Test_indexChapters(t *testing.T) {
+	// titles are compared by value, not by pointer
+	title := func(s string) *string { return &s }
+	pages := []FetchPage{
+		{PageNumber: 1},
+		{PageNumber: 2, ChapterTitle: title("上")},
+		{PageNumber: 3, ChapterTitle: title("上")},
+		{PageNumber: 4, ChapterTitle: title("下")},
+		{PageNumber: 5, ChapterTitle: title("上")},
+	}
+	want := []Chapter{
+		{Title: "上", PageNumbers: []int{2, 3}},
+		{Title: "下", PageNumbers: []int{4}},
+		{Title: "上", PageNumbers: []int{5}},
+	}
+	if got := indexChapters(pages); !reflect.DeepEqual(got, want) {
+		t.Errorf("indexChapters() = %+v, want %+v", got, want)
+	}
+}
+
+func TestClient_Fetch_errorStatus(t *testing.T) {
+	// episodes answer 404
+	c := &Client{httpClient: &http.Client{Transport: fakeContentSite(map[string]string{
+		"/n0000aa/": readTestData(t, "legacy_index.html"),
+	}, nil)}}
+	res, err := c.Fetch(context.Background(), &FetchParams{NCode: "n0000aa", WithContent: true})
+	var pe *PageError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Client.Fetch() error = %v, want *PageError", err)
+	}
+	if got := res.FailedPages(); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Errorf("FetchResult.FailedPages() = %v, want [1 2 3]", got)
+	}
+	for i, page := range res.Pages {
+		if page.Err == nil || !strings.Contains(page.Err.Error(), "status 404") {
+			t.Errorf("Client.Fetch() page %d error = %v, want status 404", i+1, page.Err)
+		}
+	}
+
+	// index answers 404
+	if res, err := c.Fetch(context.Background(), &FetchParams{NCode: "n1111aa"}); err == nil || res != nil {
+		t.Errorf("Client.Fetch() = %+v, %v, want error of index status", res, err)
+	}
+}
+
+func TestClient_Fetch_skipped(t *testing.T) {
+	c := &Client{httpClient: &http.Client{Transport: fakeContentSite(map[string]string{
+		"/n0000aa/":   readTestData(t, "legacy_index.html"),
+		"/n0000aa/3/": readTestData(t, "legacy_episode.html"),
+	}, nil)}}
+
+	res, err := c.Fetch(context.Background(), &FetchParams{NCode: "n0000aa", Page: 3})
+	if err != nil {
+		t.Fatalf("Client.Fetch() error = %v", err)
+	}
+	wantStatus := []PageStatus{PageStatusSkipped, PageStatusSkipped, PageStatusFetched}
+	for i, page := range res.Pages {
+		if page.Status != wantStatus[i] {
+			t.Errorf("Client.Fetch() page %d = %v, want %v", i+1, page.Status, wantStatus[i])
+		}
+	}
+}
+
+func TestClient_Fetch_concurrency(t *testing.T) {
+	episode := readTestData(t, "legacy_episode.html")
+	pages := map[string]string{"/n0000aa/": readTestData(t, "legacy_index.html")}
+	for i := 1; i <= 3; i++ {
+		pages[fmt.Sprintf("/n0000aa/%d/", i)] = strings.Replace(episode,
+			`<div class="novel_subtitle">つれづれなるままに`, fmt.Sprintf(`<div class="novel_subtitle">第%d話`, i), 1)
+	}
+	site := fakeContentSite(pages, nil)
+
+	var mu sync.Mutex
+	inflight, maxInflight := 0, 0
+	var both chan struct{}
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/n0000aa/" {
+			return site(req)
+		}
+		mu.Lock()
+		inflight++
+		if inflight > maxInflight {
+			maxInflight = inflight
+		}
+		wait := both
+		if inflight == 2 && both != nil {
+			// release requests when two are in flight at once
+			close(both)
+			both = nil
+		}
+		mu.Unlock()
+		if wait != nil {
+			select {
+			case <-wait:
+			case <-time.After(time.Second):
+			}
+		}
+		mu.Lock()
+		inflight--
+		mu.Unlock()
+		return site(req)
+	})
+
+	tests := []struct {
+		name   string
+		client *Client
+		params *FetchParams
+	}{
+		{"params", &Client{httpClient: &http.Client{Transport: transport}}, &FetchParams{NCode: "n0000aa", WithContent: true, Concurrency: 2}},
+		{"client", NewClient(WithHTTPClient(&http.Client{Transport: transport}), WithContentRateLimit(RateLimit{}), WithFetchConcurrency(2)), &FetchParams{NCode: "n0000aa", WithContent: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxInflight = 0
+			both = make(chan struct{})
+			res, err := tt.client.Fetch(context.Background(), tt.params)
+			if err != nil {
+				t.Fatalf("Client.Fetch() error = %v", err)
+			}
+			if maxInflight != 2 {
+				t.Errorf("Client.Fetch() max concurrent requests = %d, want 2", maxInflight)
+			}
+			for i, page := range res.Pages {
+				if want := fmt.Sprintf("第%d話", i+1); page.SubTitle != want || page.PageNumber != i+1 {
+					t.Errorf("Client.Fetch() page %d = %s, want %s", i+1, page.SubTitle, want)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_Fetch_cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	site := fakeContentSite(map[string]string{
+		"/n0000aa/":   readTestData(t, "legacy_index.html"),
+		"/n0000aa/1/": readTestData(t, "legacy_episode.html"),
+	}, nil)
+	c := &Client{httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if err := req.Context().Err(); err != nil {
+			return nil, err
+		}
+		if req.URL.Path == "/n0000aa/1/" {
+			cancel()
+		}
+		return site(req)
+	})}}
+
+	res, err := c.Fetch(ctx, &FetchParams{NCode: "n0000aa", WithContent: true, Concurrency: 1})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Client.Fetch() error = %v, want context.Canceled", err)
+	}
+	if res.Pages[0].Status != PageStatusFetched {
+		t.Errorf("Client.Fetch() page 1 = %v, want fetched", res.Pages[0].Status)
+	}
+	if got := res.FailedPages(); len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Errorf("FetchResult.FailedPages() = %v, want [2 3]", got)
+	}
+}
+
+func TestClient_Fetch_paginatedIndex(t *testing.T) {
+	page2 := readTestData(t, "current_index_p2.html")
+	tests := []struct {
+		name  string
+		page2 string
+	}{
+		{"chapter inherited", page2},
+		// heading of continued chapter is repeated on top of next index page
+		{"chapter heading repeated", strings.Replace(page2, `<div class="p-eplist__sublist">`, `<div class="p-eplist__chapter-title">上</div>
+<div class="p-eplist__sublist">`, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			site := fakeContentSite(map[string]string{
+				"/n0000aa/": readTestData(t, "current_index_p1.html"),
+			}, &requests)
+			c := &Client{httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path == "/n0000aa/" && req.URL.Query().Get("p") == "2" {
+					requests = append(requests, req.URL.RequestURI())
+					return textResponse(http.StatusOK, tt.page2), nil
+				}
+				return site(req)
+			})}}
+
+			res, err := c.Fetch(context.Background(), &FetchParams{NCode: "n0000aa"})
+			if err != nil {
+				t.Fatalf("Client.Fetch() error = %v", err)
+			}
+			if want := []string{"/n0000aa/", "/n0000aa/?p=2"}; !reflect.DeepEqual(requests, want) {
+				t.Errorf("Client.Fetch() requests = %v, want %v", requests, want)
+			}
+			if res.PageCount != 5 || len(res.Pages) != 5 || res.Title != "徒然草" {
+				t.Fatalf("Client.Fetch() = %+v", res)
+			}
+			wantChapters := []string{"序段", "上", "上", "上", "下"}
+			for i, page := range res.Pages {
+				if page.PageNumber != i+1 || page.ChapterTitle == nil || *page.ChapterTitle != wantChapters[i] {
+					t.Errorf("Client.Fetch() page %d = %+v, want chapter %s", i+1, page, wantChapters[i])
+				}
+			}
+			wantTOC := []Chapter{
+				{Title: "序段", PageNumbers: []int{1}},
+				{Title: "上", PageNumbers: []int{2, 3, 4}},
+				{Title: "下", PageNumbers: []int{5}},
+			}
+			if !reflect.DeepEqual(res.Chapters, wantTOC) {
+				t.Errorf("Client.Fetch() chapters = %+v, want %+v", res.Chapters, wantTOC)
+			}
+			last := res.Pages[4]
+			if !last.PublishDate.Equal(*jstDate(2019, 5, 10, 18, 39, 0, 0)) || last.LastUpdateDate == nil || !last.LastUpdateDate.Equal(*jstDate(2019, 6, 1, 9, 0, 0, 0)) {
+				t.Errorf("Client.Fetch() last page dates = %v, %v", last.PublishDate, last.LastUpdateDate)
+			}
+		})
+	}
+}
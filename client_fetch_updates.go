@@ -0,0 +1,142 @@
+package narrow
+
+import (
+	"context"
+	"time"
+)
+
+// FetchChanges summarizes difference between previous FetchResult and fresh index
+type FetchChanges struct {
+	// Added is page numbers of new episodes
+	Added []int
+	// Revised is page numbers of episodes whose LastUpdateDate changed
+	Revised []int
+	// Removed is page numbers in previous result which are no longer in index
+	Removed []int
+	// Renumbered is episodes whose page number changed, such as after a deletion
+	Renumbered []PageMove
+}
+
+// PageMove is page number change of an episode
+type PageMove struct {
+	From int
+	To   int
+}
+
+// HasChanges returns true if any episode is added, revised, removed or renumbered
+func (changes *FetchChanges) HasChanges() bool {
+	return len(changes.Added) > 0 || len(changes.Revised) > 0 || len(changes.Removed) > 0 || len(changes.Renumbered) > 0
+}
+
+// FetchUpdates fetches index and downloads only episodes that are new or revised since previous.
+//
+// Episodes are matched by PublishDate (and SubTitle among same PublishDate), because page numbers shift
+// when the author deletes or inserts an episode. Unchanged episodes are copied from previous,
+// previous pages which are not PageStatusFetched are downloaded again.
+// previous nil is same as Fetch with WithContent, every episode is reported as added.
+// On page failures, the result is partial and error is joined *PageError like Fetch.
+func (c *Client) FetchUpdates(ctx context.Context, params *FetchParams, previous *FetchResult) (*FetchResult, *FetchChanges, error) {
+	ctx = allowOver18(ctx, params)
+	index := *params
+	index.WithContent = false
+	index.Page = 0
+	index.Pages = nil
+	result, err := c.Fetch(ctx, &index)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if result.NovelType != 1 {
+		// short story is already fetched with index
+		return result, shortStoryChanges(result, previous), nil
+	}
+
+	changes, download := diffPages(result, previous)
+	return result, changes, c.fetchPages(ctx, result, params, download)
+}
+
+// diffPages copies unchanged pages of previous into result, returns changes and page numbers to download
+func diffPages(result, previous *FetchResult) (*FetchChanges, []int) {
+	changes := &FetchChanges{}
+	var prevPages []FetchPage
+	if previous != nil {
+		prevPages = previous.Pages
+	}
+	byDate := make(map[time.Time][]int)
+	for i, page := range prevPages {
+		key := page.PublishDate.UTC()
+		byDate[key] = append(byDate[key], i)
+	}
+	matched := make([]bool, len(prevPages))
+
+	var download []int
+	for i := range result.Pages {
+		page := &result.Pages[i]
+		pageNo := i + 1
+		prev := matchPage(page, prevPages, byDate[page.PublishDate.UTC()], matched)
+		if prev < 0 {
+			changes.Added = append(changes.Added, pageNo)
+			download = append(download, pageNo)
+			continue
+		}
+		matched[prev] = true
+		old := prevPages[prev]
+		if prev+1 != pageNo {
+			changes.Renumbered = append(changes.Renumbered, PageMove{From: prev + 1, To: pageNo})
+		}
+		if !sameTime(old.LastUpdateDate, page.LastUpdateDate) {
+			changes.Revised = append(changes.Revised, pageNo)
+			download = append(download, pageNo)
+			continue
+		}
+		if old.Status != PageStatusFetched {
+			download = append(download, pageNo)
+			continue
+		}
+		old.PageNumber = pageNo
+		old.PublishDate = page.PublishDate
+		old.LastUpdateDate = page.LastUpdateDate
+		*page = old
+	}
+	for i := range prevPages {
+		if !matched[i] {
+			changes.Removed = append(changes.Removed, i+1)
+		}
+	}
+	return changes, download
+}
+
+// matchPage returns index of unmatched previous page among candidates, same SubTitle first, or -1
+func matchPage(page *FetchPage, prevPages []FetchPage, candidates []int, matched []bool) int {
+	found := -1
+	for _, idx := range candidates {
+		if matched[idx] {
+			continue
+		}
+		if prevPages[idx].SubTitle == page.SubTitle {
+			return idx
+		}
+		if found < 0 {
+			found = idx
+		}
+	}
+	return found
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+func shortStoryChanges(result, previous *FetchResult) *FetchChanges {
+	changes := &FetchChanges{}
+	switch {
+	case previous == nil || len(previous.Pages) == 0:
+		changes.Added = []int{1}
+	case previous.Pages[0].rawHTML != result.Pages[0].rawHTML:
+		changes.Revised = []int{1}
+	}
+	return changes
+}
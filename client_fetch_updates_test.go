@@ -0,0 +1,104 @@
+package narrow
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClient_FetchUpdates(t *testing.T) {
+	index := readTestData(t, "legacy_index.html")
+	episode := readTestData(t, "legacy_episode.html")
+	// episode 2 deleted, episode 1 revised again, episode 4 posted
+	updated := strings.Replace(index, `<dl class="novel_sublist2">
+<dd class="subtitle"><a href="/n0000aa/2/">いでや、この世に生れては</a></dd>
+<dt class="long_update">
+2019/05/07 18:39
+</dt>
+</dl>
+`, "", 1)
+	updated = strings.Replace(updated, "2019/05/07 10:00 改稿", "2019/06/01 09:00 改稿", 1)
+	updated = strings.Replace(updated, "/n0000aa/3/", "/n0000aa/2/", 1)
+	updated = strings.Replace(updated, "</div>\n</div>\n</body>", `<dl class="novel_sublist2">
+<dd class="subtitle"><a href="/n0000aa/3/">あやしうこそものぐるほしけれ</a></dd>
+<dt class="long_update">
+2019/06/02 18:39
+</dt>
+</dl>
+</div>
+</div>
+</body>`, 1)
+
+	pages := map[string]string{
+		"/n0000aa/":   index,
+		"/n0000aa/1/": episode,
+		"/n0000aa/2/": episode,
+		"/n0000aa/3/": episode,
+	}
+	var requests []string
+	c := &Client{httpClient: &http.Client{Transport: fakeContentSite(pages, &requests)}}
+	params := &FetchParams{NCode: "n0000aa", WithContent: true}
+
+	previous, changes, err := c.FetchUpdates(context.Background(), params, nil)
+	if err != nil {
+		t.Fatalf("Client.FetchUpdates() error = %v", err)
+	}
+	if want := (&FetchChanges{Added: []int{1, 2, 3}}); !reflect.DeepEqual(changes, want) {
+		t.Errorf("Client.FetchUpdates(nil) changes = %+v, want %+v", changes, want)
+	}
+
+	pages["/n0000aa/"] = updated
+	requests = nil
+	res, changes, err := c.FetchUpdates(context.Background(), params, previous)
+	if err != nil {
+		t.Fatalf("Client.FetchUpdates() error = %v", err)
+	}
+	want := &FetchChanges{
+		Added:      []int{3},
+		Revised:    []int{1},
+		Removed:    []int{2},
+		Renumbered: []PageMove{{From: 3, To: 2}},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Client.FetchUpdates() changes = %+v, want %+v", changes, want)
+	}
+	if wantReq := []string{"/n0000aa/", "/n0000aa/1/", "/n0000aa/3/"}; !reflect.DeepEqual(requests, wantReq) {
+		t.Errorf("Client.FetchUpdates() requests = %v, want %v", requests, wantReq)
+	}
+	if res.PageCount != 3 {
+		t.Fatalf("Client.FetchUpdates() PageCount = %d, want 3", res.PageCount)
+	}
+	for i, page := range res.Pages {
+		if page.Status != PageStatusFetched || page.PageNumber != i+1 || len(page.Lines) != 3 {
+			t.Errorf("Client.FetchUpdates() page %d = %+v", i+1, page)
+		}
+	}
+	if !res.Pages[1].PublishDate.Equal(*jstDate(2019, 5, 8, 18, 39, 0, 0)) {
+		t.Errorf("Client.FetchUpdates() renumbered page date = %v", res.Pages[1].PublishDate)
+	}
+
+	requests = nil
+	_, changes, err = c.FetchUpdates(context.Background(), params, res)
+	if err != nil {
+		t.Fatalf("Client.FetchUpdates() error = %v", err)
+	}
+	if changes.HasChanges() || len(requests) != 1 {
+		t.Errorf("Client.FetchUpdates() unchanged = %+v, requests %v", changes, requests)
+	}
+
+	// episodes of R18 novel need over18 cookie as well as index
+	cookies := make(map[string]string)
+	r18 := &Client{httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		cookies[req.URL.Path] = req.Header.Get("Cookie")
+		return fakeContentSite(pages, nil)(req)
+	})}}
+	r18Params := &FetchParams{Site: FetchSiteNocturne, NCode: "n0000aa", WithContent: true, AllowOver18: true}
+	if _, _, err := r18.FetchUpdates(context.Background(), r18Params, previous); err != nil {
+		t.Fatalf("Client.FetchUpdates() r18 error = %v", err)
+	}
+	if want := map[string]string{"/n0000aa/": "over18=yes", "/n0000aa/1/": "over18=yes", "/n0000aa/3/": "over18=yes"}; !reflect.DeepEqual(cookies, want) {
+		t.Errorf("Client.FetchUpdates() r18 cookies = %v, want %v", cookies, want)
+	}
+}
@@ -0,0 +1,264 @@
+package narrow
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// NovelDetail contains novel information scraped from `/novelview/infotop/ncode/<ncode>/`
+type NovelDetail struct {
+	Site  FetchSite
+	NCode string
+
+	// 小説名
+	Title string
+	// あらすじ
+	Story string
+	// 作者名
+	Writer string
+	// ユーザーID, R18 では空
+	UserID string
+	// R18 作者の xid, 一般では空
+	XID string
+	// 所属シリーズ, なければ nil
+	Series *SeriesLink
+	// ジャンル, such as `ハイファンタジー〔ファンタジー〕`
+	Genre string
+	// キーワード, 必須キーワードを含む
+	Keywords []string
+
+	// 必須キーワードに「R15」を含む
+	IsR15 bool
+	// 必須キーワードにボーイズラブを含む
+	IsBoysLove bool
+	// 必須キーワードにガールズラブを含む
+	IsGirlsLove bool
+	// 必須キーワードに残酷な描写ありを含む
+	IsZankoku bool
+	// 必須キーワードに異世界転生を含む
+	IsTensei bool
+	// 必須キーワードに異世界転移を含む
+	IsTenni bool
+
+	// 掲載日
+	PublishDate time.Time
+	// 最新部分掲載日 or 最終部分掲載日, zero for short story
+	LastPublishDate time.Time
+
+	// 感想数
+	ImpressionCount int
+	// レビュー数
+	ReviewCount int
+	// ブックマーク登録数
+	BookmarkCount int
+	// 総合評価ポイント
+	GlobalPoint int
+	// 文字数
+	Length int
+
+	// 感想受付
+	AcceptsImpression bool
+	// レビュー受付
+	AcceptsReview bool
+}
+
+// SeriesLink is series which a novel belongs to
+type SeriesLink struct {
+	// SCode is series code such as `s1234a`
+	SCode string
+	Title string
+}
+
+// FetchInfo downloads novel info page of ncode, R18 sites are fetched with over18 cookie
+func (c *Client) FetchInfo(ctx context.Context, site FetchSite, ncode string) (*NovelDetail, error) {
+	params := &FetchParams{Site: site, NCode: strings.ToLower(ncode), AllowOver18: true}
+	u, err := params.toInfoURL(c.contentBase(site))
+	if err != nil {
+		return nil, err
+	}
+	ctx = allowOver18(ctx, params)
+
+	status, body, fromCache, err := c.getBody(ctx, endpointIndex, u)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("fetch info %s: status %d", u, status)
+	}
+
+	logger := c.log().With("ncode", params.NCode, "url", u.String())
+	detail, err := parseNovelInfo(bytes.NewReader(body), logger)
+	if err != nil {
+		return nil, err
+	}
+	if !fromCache {
+		c.storeCache(endpointIndex, u.String(), body)
+	}
+	detail.Site = site
+	detail.NCode = params.NCode
+	return detail, nil
+}
+
+// toInfoURL returns `<base>/novelview/infotop/ncode/<ncode>/`
+func (params *FetchParams) toInfoURL(base string) (*url.URL, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = fmt.Sprintf("%s/novelview/infotop/ncode/%s/", strings.TrimSuffix(u.Path, "/"), params.NCode)
+	return u, nil
+}
+
+var (
+	mypageRe  = regexp.MustCompile(`//mypage\.syosetu\.com/(?:mypage/top/userid/)?([0-9]+)/?`)
+	xmypageRe = regexp.MustCompile(`//xmypage\.syosetu\.com/(?:mypage/top/xid/)?(x[0-9a-z]+)/?`)
+	seriesRe  = regexp.MustCompile(`^/(s[0-9]+[a-z]+)/?$`)
+	numberRe  = regexp.MustCompile(`[0-9][0-9,]*`)
+)
+
+// infoDateFormats are 掲載日 formats of legacy and current layout
+var infoDateFormats = []string{"2006年 01月02日 15時04分", "2006年01月02日 15時04分", novelUpdateTimeFormat}
+
+// parseNovelInfo reads label and value pairs of `th`/`td` (legacy) or `dt`/`dd` (current) in info page
+func parseNovelInfo(r io.Reader, logger *slog.Logger) (*NovelDetail, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &NovelDetail{}
+	detail.Title = strings.TrimSpace(doc.Find("h1").First().Text())
+
+	fields := make(map[string]*goquery.Selection)
+	doc.Find("th, dt").Each(func(_ int, label *goquery.Selection) {
+		value := label.NextFiltered("td, dd")
+		if value.Size() != 0 {
+			fields[strings.TrimSpace(label.Text())] = value
+		}
+	})
+	text := func(labels ...string) string {
+		for _, l := range labels {
+			if v, ok := fields[l]; ok {
+				return strings.TrimSpace(v.Text())
+			}
+		}
+		return ""
+	}
+	number := func(labels ...string) int {
+		s := numberRe.FindString(text(labels...))
+		n, _ := strconv.Atoi(strings.ReplaceAll(s, ",", ""))
+		return n
+	}
+	date := func(labels ...string) time.Time {
+		s := text(labels...)
+		if s == "" {
+			return time.Time{}
+		}
+		t, err := infoDate(s)
+		if err != nil {
+			logger.Warn("info date parse failed", "label", labels[0], "value", s, "error", err)
+		}
+		return t
+	}
+
+	detail.Story = text("あらすじ")
+	detail.Writer = text("作者名")
+	if writer, ok := fields["作者名"]; ok {
+		writer.Find("a").Each(func(_ int, a *goquery.Selection) {
+			href, _ := a.Attr("href")
+			if m := mypageRe.FindStringSubmatch(href); m != nil {
+				detail.UserID = m[1]
+			}
+			if m := xmypageRe.FindStringSubmatch(href); m != nil {
+				detail.XID = m[1]
+			}
+		})
+	}
+	detail.Genre = text("ジャンル")
+	detail.Keywords = strings.Fields(text("キーワード"))
+	for _, k := range detail.Keywords {
+		switch k {
+		case "R15":
+			detail.IsR15 = true
+		case "ボーイズラブ":
+			detail.IsBoysLove = true
+		case "ガールズラブ":
+			detail.IsGirlsLove = true
+		case "残酷な描写あり":
+			detail.IsZankoku = true
+		case "異世界転生":
+			detail.IsTensei = true
+		case "異世界転移":
+			detail.IsTenni = true
+		}
+	}
+
+	detail.PublishDate = date("掲載日")
+	detail.LastPublishDate = date("最新部分掲載日", "最終部分掲載日", "最終掲載日")
+	detail.ImpressionCount = number("感想")
+	detail.ReviewCount = number("レビュー")
+	detail.BookmarkCount = number("ブックマーク登録", "ブックマーク")
+	detail.GlobalPoint = number("総合評価")
+	detail.Length = number("文字数")
+	detail.AcceptsImpression = accepts("感想受付", text("感想受付"), logger)
+	detail.AcceptsReview = accepts("レビュー受付", text("レビュー受付"), logger)
+
+	// only series row of info table, other links such as recommendations may point other series
+	if series, ok := fields["シリーズ"]; ok {
+		series.Find("a[href]").EachWithBreak(func(_ int, a *goquery.Selection) bool {
+			href, _ := a.Attr("href")
+			ref, err := url.Parse(href)
+			if err != nil {
+				return true
+			}
+			m := seriesRe.FindStringSubmatch(ref.Path)
+			if m == nil {
+				return true
+			}
+			detail.Series = &SeriesLink{SCode: m[1], Title: strings.TrimSpace(a.Text())}
+			return false
+		})
+	}
+
+	return detail, nil
+}
+
+func infoDate(s string) (time.Time, error) {
+	for _, format := range infoDateFormats {
+		if t, err := time.ParseInLocation(format, s, jst); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unknown date format %q", s)
+}
+
+// acceptsValues are values of 感想受付 and レビュー受付
+var acceptsValues = map[string]bool{
+	"受け付けています":   true,
+	"受け付けております":  true,
+	"受け付けていません":  false,
+	"受け付けておりません": false,
+}
+
+// accepts returns setting of label, unknown value is treated as off
+func accepts(label, s string, logger *slog.Logger) bool {
+	if s == "" {
+		return false
+	}
+	on, ok := acceptsValues[strings.TrimSuffix(s, "。")]
+	if !ok {
+		logger.Warn("unknown accepts value", "label", label, "value", s)
+	}
+	return on
+}
@@ -0,0 +1,99 @@
+package narrow
+
+import (
+	"context"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func Test_parseNovelInfo_golden(t *testing.T) {
+	discard := slog.New(slog.NewTextHandler(ioutil.Discard, nil))
+	for _, name := range []string{"legacy_infotop", "current_infotop"} {
+		t.Run(name, func(t *testing.T) {
+			detail, err := parseNovelInfo(strings.NewReader(readTestData(t, name+".html")), discard)
+			if err != nil {
+				t.Fatalf("parseNovelInfo() error = %v", err)
+			}
+			assertGolden(t, name, detail)
+		})
+	}
+}
+
+func Test_accepts(t *testing.T) {
+	discard := slog.New(slog.NewTextHandler(ioutil.Discard, nil))
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"受け付けています。", true},
+		{"受け付けております", true},
+		{"受け付けておりません。", false},
+		{"受け付けていません。", false},
+		// unknown value is off
+		{"-", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := accepts("感想受付", tt.value, discard); got != tt.want {
+			t.Errorf("accepts(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func Test_parseNovelInfo_seriesRow(t *testing.T) {
+	// series link outside info table is not the series of the novel
+	html := `<html><body><h1>t</h1><div><a href="/s5555x/">other</a></div>
+<table><tr><th>作者名</th><td>a</td></tr></table></body></html>`
+	detail, err := parseNovelInfo(strings.NewReader(html), slog.New(slog.NewTextHandler(ioutil.Discard, nil)))
+	if err != nil {
+		t.Fatalf("parseNovelInfo() error = %v", err)
+	}
+	if detail.Series != nil {
+		t.Errorf("parseNovelInfo() series = %+v, want nil", detail.Series)
+	}
+}
+
+func TestClient_FetchInfo(t *testing.T) {
+	var cookies []string
+	site := fakeContentSite(map[string]string{
+		"/novelview/infotop/ncode/n0000aa/": readTestData(t, "legacy_infotop.html"),
+		"/novelview/infotop/ncode/n9999zz/": readTestData(t, "current_infotop.html"),
+	}, nil)
+	c := &Client{httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		cookies = append(cookies, req.Header.Get("Cookie"))
+		return site(req)
+	})}}
+
+	tests := []struct {
+		name       string
+		site       FetchSite
+		ncode      string
+		wantCookie string
+		wantErr    bool
+	}{
+		{"narou", FetchSiteNarou, "N0000AA", "", false},
+		{"r18", FetchSiteMoonLight, "n9999zz", "over18=yes", false},
+		{"not found", FetchSiteNarou, "n1111aa", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cookies = nil
+			got, err := c.FetchInfo(context.Background(), tt.site, tt.ncode)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Client.FetchInfo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(cookies) != 1 || cookies[0] != tt.wantCookie {
+				t.Errorf("Client.FetchInfo() cookies = %q, want %q", cookies, tt.wantCookie)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Site != tt.site || got.NCode != strings.ToLower(tt.ncode) || got.Series == nil {
+				t.Errorf("Client.FetchInfo() = %+v", got)
+			}
+		})
+	}
+}
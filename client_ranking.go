@@ -0,0 +1,193 @@
+package narrow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// NarouRankingAPIEndPoint is Narou ranking api endpoint
+const NarouRankingAPIEndPoint = "https://api.syosetu.com/rank/rankget/"
+
+// RankingType is ranking period
+type RankingType int
+
+// ranking types
+const (
+	RankingTypeDaily RankingType = iota
+	RankingTypeWeekly
+	RankingTypeMonthly
+	RankingTypeQuarterly
+)
+
+var rankingTypeCodes = map[RankingType]string{
+	RankingTypeDaily:     "d",
+	RankingTypeWeekly:    "w",
+	RankingTypeMonthly:   "m",
+	RankingTypeQuarterly: "q",
+}
+
+const keyRankingType = "rtype"
+
+// rankingEpoch is the first date of ranking api
+var rankingEpoch = time.Date(2013, 5, 1, 0, 0, 0, 0, jst)
+
+// RankingEntry is a novel in ranking
+type RankingEntry struct {
+	Rank  int
+	NCode string
+	Point int
+	// Novel is set by RankingWithNovelInfo, nil if search api did not return the novel
+	Novel *NovelInfo
+}
+
+type rankingResponse struct {
+	NCode string `json:"ncode"`
+	Point int    `json:"pt"`
+	Rank  int    `json:"rank"`
+}
+
+// RankingOption configures Ranking
+type RankingOption func(*rankingOptions)
+
+type rankingOptions struct {
+	withNovelInfo bool
+}
+
+// RankingWithNovelInfo fills RankingEntry.Novel by searching ncodes of ranking in batches
+func RankingWithNovelInfo() RankingOption {
+	return func(o *rankingOptions) { o.withNovelInfo = true }
+}
+
+// rankingSearchBatch is number of ncodes in one search request
+const rankingSearchBatch = 100
+
+// Ranking returns ranking of date (JST) and type.
+// Weekly ranking is published on Tuesday, monthly and quarterly on the 1st of month.
+func (c *Client) Ranking(ctx context.Context, date time.Time, rt RankingType, opts ...RankingOption) ([]RankingEntry, error) {
+	o := &rankingOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	rtype, err := rankingTypeValue(date, rt)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(NarouRankingAPIEndPoint)
+	if err != nil {
+		return nil, err
+	}
+	vs := url.Values{}
+	vs.Set(outputFormatKey, outputFormat)
+	vs.Set(keyRankingType, rtype)
+	u.RawQuery = vs.Encode()
+
+	var responses []rankingResponse
+	if err := c.getAPIJSON(ctx, u, &responses); err != nil {
+		return nil, err
+	}
+	entries := make([]RankingEntry, len(responses))
+	for i, r := range responses {
+		entries[i] = RankingEntry{Rank: r.Rank, NCode: r.NCode, Point: r.Point}
+	}
+
+	if o.withNovelInfo {
+		if err := c.enrichRanking(ctx, entries); err != nil {
+			return entries, err
+		}
+	}
+	return entries, nil
+}
+
+// rankingTypeValue returns `rtype` such as `20130501-d`, error is *ValidationError
+func rankingTypeValue(date time.Time, rt RankingType) (string, error) {
+	var errs paramErrors
+	code, ok := rankingTypeCodes[rt]
+	if !ok {
+		errs.add(keyRankingType, int(rt), ErrUnknownValue)
+		_, err := errs.result()
+		return "", err
+	}
+
+	d := date.In(jst)
+	value := fmt.Sprintf("%s-%s", d.Format("20060102"), code)
+	day := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, jst)
+	switch {
+	case day.Before(rankingEpoch):
+		errs.add(keyRankingType, value, ErrOutOfRange)
+	case rt == RankingTypeWeekly && d.Weekday() != time.Tuesday:
+		errs.add(keyRankingType, value, ErrInvalidDate)
+	case (rt == RankingTypeMonthly || rt == RankingTypeQuarterly) && d.Day() != 1:
+		errs.add(keyRankingType, value, ErrInvalidDate)
+	}
+	if _, err := errs.result(); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// getAPIJSON gets u from api host and decodes json body into v
+func (c *Client) getAPIJSON(ctx context.Context, u *url.URL, v interface{}) error {
+	u, err := c.rebaseAPIURL(u)
+	if err != nil {
+		return err
+	}
+	status, body, fromCache, err := c.getBody(ctx, endpointAPI, u)
+	if err != nil {
+		return err
+	}
+	if status < 200 || status >= 300 {
+		return newAPIError(status, u.String(), body)
+	}
+	decoded, err := decompressBody(body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(decoded, v); err != nil {
+		return newAPIError(status, u.String(), decoded)
+	}
+	if !fromCache {
+		c.storeCache(endpointAPI, u.String(), body)
+	}
+	return nil
+}
+
+func (c *Client) enrichRanking(ctx context.Context, entries []RankingEntry) error {
+	index := make(map[string][]int)
+	var ncodes []string
+	for i, e := range entries {
+		key := strings.ToLower(e.NCode)
+		if _, ok := index[key]; !ok {
+			ncodes = append(ncodes, e.NCode)
+		}
+		index[key] = append(index[key], i)
+	}
+
+	var errs []error
+	for start := 0; start < len(ncodes); start += rankingSearchBatch {
+		end := min(start+rankingSearchBatch, len(ncodes))
+		params := NewSearchParams()
+		params.AddNCodes(ncodes[start:end])
+		params.SetLimit(end - start)
+		res, err := c.Search(ctx, params)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for i := range res.NovelInfos {
+			info := &res.NovelInfos[i]
+			if info.NCode == nil {
+				continue
+			}
+			for _, idx := range index[strings.ToLower(*info.NCode)] {
+				entries[idx].Novel = info
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
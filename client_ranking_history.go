@@ -0,0 +1,115 @@
+package narrow
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NarouRankingHistoryAPIEndPoint is Narou ranking history (rankin) api endpoint
+const NarouRankingHistoryAPIEndPoint = "https://api.syosetu.com/rank/rankin/"
+
+// RankingRecord is a ranking the novel appeared in
+type RankingRecord struct {
+	// Date is ranking date in JST
+	Date  time.Time
+	Type  RankingType
+	Rank  int
+	Point int
+}
+
+// RankingHistory is time ordered ranking records of a novel
+type RankingHistory []RankingRecord
+
+type rankingHistoryResponse struct {
+	RankingType string `json:"rtype"`
+	Point       int    `json:"pt"`
+	Rank        int    `json:"rank"`
+}
+
+// RankingHistory returns every ranking ncode appeared in, ordered by date then RankingType
+func (c *Client) RankingHistory(ctx context.Context, ncode string) (RankingHistory, error) {
+	if !ncodeRe.MatchString(ncode) {
+		var errs paramErrors
+		errs.add(keyNCode, ncode, ErrInvalidNCode)
+		_, err := errs.result()
+		return nil, err
+	}
+	u, err := url.Parse(NarouRankingHistoryAPIEndPoint)
+	if err != nil {
+		return nil, err
+	}
+	vs := url.Values{}
+	vs.Set(outputFormatKey, outputFormat)
+	vs.Set(keyNCode, strings.ToUpper(ncode))
+	u.RawQuery = vs.Encode()
+
+	var responses []rankingHistoryResponse
+	if err := c.getAPIJSON(ctx, u, &responses); err != nil {
+		return nil, err
+	}
+
+	history := make(RankingHistory, 0, len(responses))
+	for _, r := range responses {
+		date, rt, ok := parseRankingTypeValue(r.RankingType)
+		if !ok {
+			c.log().Warn("unknown rtype in ranking history", "ncode", ncode, "value", r.RankingType)
+			continue
+		}
+		history = append(history, RankingRecord{Date: date, Type: rt, Rank: r.Rank, Point: r.Point})
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		if !history[i].Date.Equal(history[j].Date) {
+			return history[i].Date.Before(history[j].Date)
+		}
+		return history[i].Type < history[j].Type
+	})
+	return history, nil
+}
+
+// parseRankingTypeValue parses `rtype` such as `20130501-d`
+func parseRankingTypeValue(value string) (time.Time, RankingType, bool) {
+	d, code, ok := strings.Cut(value, "-")
+	if !ok {
+		return time.Time{}, 0, false
+	}
+	date, err := time.ParseInLocation("20060102", d, jst)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	for rt, c := range rankingTypeCodes {
+		if c == code {
+			return date, rt, true
+		}
+	}
+	return time.Time{}, 0, false
+}
+
+// BestRank returns record of the highest rank in rt, earliest one among ties
+func (history RankingHistory) BestRank(rt RankingType) (RankingRecord, bool) {
+	var best RankingRecord
+	found := false
+	for _, r := range history {
+		if r.Type != rt {
+			continue
+		}
+		if !found || r.Rank < best.Rank {
+			best = r
+			found = true
+		}
+	}
+	return best, found
+}
+
+// DaysInRanking returns number of rankings of rt the novel appeared in, such as number of weeks for weekly
+func (history RankingHistory) DaysInRanking(rt RankingType) int {
+	n := 0
+	for _, r := range history {
+		if r.Type == rt {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,87 @@
+package narrow
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClient_RankingHistory(t *testing.T) {
+	body := `[
+{"rtype":"20190508-d","pt":120,"rank":8},
+{"rtype":"20190507-w","pt":300,"rank":40},
+{"rtype":"20190507-d","pt":200,"rank":3},
+{"rtype":"20190506-d","pt":100,"rank":3},
+{"rtype":"20190601-m","pt":900,"rank":12},
+{"rtype":"broken","pt":1,"rank":1}
+]`
+	var ncode string
+	c := &Client{httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/rank/rankin/" {
+			return textResponse(http.StatusNotFound, "not found"), nil
+		}
+		ncode = req.URL.Query().Get("ncode")
+		return textResponse(http.StatusOK, body), nil
+	})}}
+
+	history, err := c.RankingHistory(context.Background(), "n0000aa")
+	if err != nil {
+		t.Fatalf("Client.RankingHistory() error = %v", err)
+	}
+	if ncode != "N0000AA" {
+		t.Errorf("Client.RankingHistory() ncode = %s", ncode)
+	}
+	want := RankingHistory{
+		{time.Date(2019, 5, 6, 0, 0, 0, 0, jst), RankingTypeDaily, 3, 100},
+		{time.Date(2019, 5, 7, 0, 0, 0, 0, jst), RankingTypeDaily, 3, 200},
+		{time.Date(2019, 5, 7, 0, 0, 0, 0, jst), RankingTypeWeekly, 40, 300},
+		{time.Date(2019, 5, 8, 0, 0, 0, 0, jst), RankingTypeDaily, 8, 120},
+		{time.Date(2019, 6, 1, 0, 0, 0, 0, jst), RankingTypeMonthly, 12, 900},
+	}
+	if len(history) != len(want) {
+		t.Fatalf("Client.RankingHistory() = %+v, want %+v", history, want)
+	}
+	for i := range want {
+		if !history[i].Date.Equal(want[i].Date) || history[i].Type != want[i].Type || history[i].Rank != want[i].Rank || history[i].Point != want[i].Point {
+			t.Errorf("Client.RankingHistory()[%d] = %+v, want %+v", i, history[i], want[i])
+		}
+	}
+
+	if _, err := c.RankingHistory(context.Background(), "0000aa"); !errors.Is(err, ErrInvalidNCode) {
+		t.Errorf("Client.RankingHistory() error = %v, want ErrInvalidNCode", err)
+	}
+}
+
+func TestRankingHistory_helpers(t *testing.T) {
+	history := RankingHistory{
+		{time.Date(2019, 5, 6, 0, 0, 0, 0, jst), RankingTypeDaily, 3, 100},
+		{time.Date(2019, 5, 7, 0, 0, 0, 0, jst), RankingTypeDaily, 3, 200},
+		{time.Date(2019, 5, 7, 0, 0, 0, 0, jst), RankingTypeWeekly, 40, 300},
+		{time.Date(2019, 5, 8, 0, 0, 0, 0, jst), RankingTypeDaily, 8, 120},
+	}
+	tests := []struct {
+		name     string
+		rt       RankingType
+		wantBest int
+		wantDate time.Time
+		wantOK   bool
+		wantDays int
+	}{
+		{"daily earliest of ties", RankingTypeDaily, 3, time.Date(2019, 5, 6, 0, 0, 0, 0, jst), true, 3},
+		{"weekly", RankingTypeWeekly, 40, time.Date(2019, 5, 7, 0, 0, 0, 0, jst), true, 1},
+		{"never in quarterly", RankingTypeQuarterly, 0, time.Time{}, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			best, ok := history.BestRank(tt.rt)
+			if ok != tt.wantOK || best.Rank != tt.wantBest || !best.Date.Equal(tt.wantDate) {
+				t.Errorf("RankingHistory.BestRank() = %+v, %v", best, ok)
+			}
+			if got := history.DaysInRanking(tt.rt); got != tt.wantDays {
+				t.Errorf("RankingHistory.DaysInRanking() = %d, want %d", got, tt.wantDays)
+			}
+		})
+	}
+}
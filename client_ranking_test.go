@@ -0,0 +1,101 @@
+package narrow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_rankingTypeValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		date    time.Time
+		rt      RankingType
+		want    string
+		wantErr error
+	}{
+		{"daily", time.Date(2019, 5, 6, 12, 0, 0, 0, jst), RankingTypeDaily, "20190506-d", nil},
+		{"daily in utc is jst date", time.Date(2019, 5, 5, 15, 0, 0, 0, time.UTC), RankingTypeDaily, "20190506-d", nil},
+		{"weekly tuesday", time.Date(2019, 5, 7, 0, 0, 0, 0, jst), RankingTypeWeekly, "20190507-w", nil},
+		{"weekly not tuesday", time.Date(2019, 5, 8, 0, 0, 0, 0, jst), RankingTypeWeekly, "", ErrInvalidDate},
+		{"monthly 1st", time.Date(2019, 5, 1, 0, 0, 0, 0, jst), RankingTypeMonthly, "20190501-m", nil},
+		{"monthly not 1st", time.Date(2019, 5, 2, 0, 0, 0, 0, jst), RankingTypeMonthly, "", ErrInvalidDate},
+		{"quarterly 1st", time.Date(2019, 6, 1, 0, 0, 0, 0, jst), RankingTypeQuarterly, "20190601-q", nil},
+		{"quarterly not 1st", time.Date(2019, 6, 30, 0, 0, 0, 0, jst), RankingTypeQuarterly, "", ErrInvalidDate},
+		{"first day", time.Date(2013, 5, 1, 0, 0, 0, 0, jst), RankingTypeDaily, "20130501-d", nil},
+		{"before api", time.Date(2013, 4, 30, 0, 0, 0, 0, jst), RankingTypeDaily, "", ErrOutOfRange},
+		{"unknown type", time.Date(2019, 5, 6, 0, 0, 0, 0, jst), RankingType(9), "", ErrUnknownValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rankingTypeValue(tt.date, tt.rt)
+			if !errors.Is(err, tt.wantErr) || (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("rankingTypeValue() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("rankingTypeValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Ranking(t *testing.T) {
+	var searches []string
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/rank/rankget/":
+			if got := req.URL.Query().Get("rtype"); got != "20190507-w" {
+				t.Errorf("rtype = %s", got)
+			}
+			var res []map[string]interface{}
+			for i := 1; i <= 150; i++ {
+				res = append(res, map[string]interface{}{"ncode": fmt.Sprintf("N%04dA", i), "pt": 1000 - i, "rank": i})
+			}
+			body, _ := json.Marshal(res)
+			return textResponse(http.StatusOK, string(body)), nil
+		case "/novelapi/api/":
+			ncodes := strings.Split(req.URL.Query().Get("ncode"), "-")
+			searches = append(searches, req.URL.Query().Get("ncode"))
+			res := []map[string]interface{}{{"allcount": len(ncodes)}}
+			for _, n := range ncodes {
+				res = append(res, map[string]interface{}{"ncode": n, "title": "title " + n})
+			}
+			body, _ := json.Marshal(res)
+			return textResponse(http.StatusOK, string(body)), nil
+		}
+		return textResponse(http.StatusNotFound, "not found"), nil
+	})
+	c := &Client{httpClient: &http.Client{Transport: transport}}
+	date := time.Date(2019, 5, 7, 0, 0, 0, 0, jst)
+
+	got, err := c.Ranking(context.Background(), date, RankingTypeWeekly)
+	if err != nil {
+		t.Fatalf("Client.Ranking() error = %v", err)
+	}
+	if len(got) != 150 || got[0] != (RankingEntry{Rank: 1, NCode: "N0001A", Point: 999}) || len(searches) != 0 {
+		t.Errorf("Client.Ranking() = %+v", got[0])
+	}
+
+	got, err = c.Ranking(context.Background(), date, RankingTypeWeekly, RankingWithNovelInfo())
+	if err != nil {
+		t.Fatalf("Client.Ranking() error = %v", err)
+	}
+	if len(searches) != 2 {
+		t.Errorf("Client.Ranking() searches = %d, want 2 batches", len(searches))
+	}
+	for _, e := range got {
+		if e.Novel == nil || e.Novel.Title == nil || *e.Novel.Title != "title "+e.NCode {
+			t.Errorf("Client.Ranking() entry = %+v", e)
+			break
+		}
+	}
+
+	if _, err := c.Ranking(context.Background(), date.AddDate(0, 0, 1), RankingTypeWeekly); !errors.Is(err, ErrInvalidDate) {
+		t.Errorf("Client.Ranking() error = %v, want ErrInvalidDate", err)
+	}
+}
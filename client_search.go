@@ -1,11 +1,15 @@
 package narrow
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"regexp"
 	"strings"
 	"time"
 )
@@ -16,42 +20,56 @@ func (c *Client) Search(ctx context.Context, params Params) (*SearchResult, erro
 	if err != nil {
 		return nil, err
 	}
-
-	req, err := http.NewRequest("GET", u.String(), nil)
+	u, err = c.rebaseAPIURL(u)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("user-agent", userAgent)
-
-	req = req.WithContext(ctx)
-
-	res, err := c.httpClient.Do(req)
+	status, body, fromCache, err := c.getBody(ctx, endpointAPI, u)
 	if err != nil {
 		return nil, err
 	}
 
-	defer res.Body.Close()
-	body, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
+	if status < 200 || status >= 300 {
+		return nil, newAPIError(status, u.String(), body)
 	}
 
 	result, err := parseSearchResponse(body)
 	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			apiErr.StatusCode = status
+			apiErr.URL = u.String()
+		}
 		return nil, err
 	}
+	if !fromCache {
+		c.storeCache(endpointAPI, u.String(), body)
+	}
+	if _, ok := params.(*SearchR18Params); ok {
+		for i := range result.NovelInfos {
+			result.NovelInfos[i].fromSearchX = true
+		}
+	}
 
 	return result, nil
 }
 
 func parseSearchResponse(body []byte) (*SearchResult, error) {
-	var responses []searchResponse
-	err := json.Unmarshal(body, &responses)
+	body, err := decompressBody(body)
 	if err != nil {
 		return nil, err
 	}
 
+	var responses []searchResponse
+	err = json.Unmarshal(body, &responses)
+	if err != nil {
+		return nil, newAPIError(0, "", body)
+	}
+	if len(responses) == 0 || responses[0].AllCount == nil {
+		return nil, &APIError{Message: errorMessage(body), Err: ErrEmptyResponse}
+	}
+
 	res := &SearchResult{
 		AllCount:   *responses[0].AllCount,
 		NovelInfos: toNovelInfos(responses[1:]),
@@ -59,6 +77,59 @@ func parseSearchResponse(body []byte) (*SearchResult, error) {
 	return res, nil
 }
 
+// newAPIError makes APIError from error response, body may be json error document, html or plain text
+func newAPIError(status int, u string, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: status, URL: u, Message: errorMessage(body)}
+	switch {
+	case status == http.StatusTooManyRequests:
+		apiErr.Err = ErrRateLimited
+	case status == http.StatusServiceUnavailable || strings.Contains(string(body), "メンテナンス"):
+		apiErr.Err = ErrMaintenance
+	}
+	return apiErr
+}
+
+const maxErrorMessageLen = 200
+
+func errorMessage(body []byte) string {
+	var doc struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &doc); err == nil {
+		if doc.Error != "" {
+			return doc.Error
+		}
+		if doc.Message != "" {
+			return doc.Message
+		}
+	}
+
+	msg := strings.TrimSpace(htmlTagRe.ReplaceAllString(string(body), " "))
+	msg = strings.Join(strings.Fields(msg), " ")
+	if r := []rune(msg); len(r) > maxErrorMessageLen {
+		msg = string(r[:maxErrorMessageLen]) + "..."
+	}
+	return msg
+}
+
+var htmlTagRe = regexp.MustCompile(`<[^>]*>`)
+
+// decompressBody inflates body compressed by `gzip` parameter, plain body is returned as is
+func decompressBody(body []byte) ([]byte, error) {
+	if !bytes.HasPrefix(body, gzipMagic) {
+		return body, nil
+	}
+	r, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
 func toNovelInfos(responses []searchResponse) []NovelInfo {
 	novels := make([]NovelInfo, len(responses))
 	for i, res := range responses {
@@ -78,6 +149,10 @@ func (res *searchResponse) toNoevlInfo() NovelInfo {
 	info.Story = res.Story
 	info.BigGenre = res.BigGenre
 	info.Genre = res.Genre
+	info.NocturneGenre = res.NocGenre
+	info.XID = res.XID
+	// only R18 api returns nocgenre and xid
+	info.fromSearchX = res.NocGenre != nil || res.XID != nil
 	if res.Keyword != nil {
 		info.Keywords = strings.Split(*res.Keyword, " ")
 	} else {
@@ -146,6 +221,10 @@ type searchResponse struct {
 	BigGenre *int `json:"biggenre"`
 	// genre
 	Genre *int `json:"genre"`
+	// 掲載サイト (R18 only)
+	NocGenre *int `json:"nocgenre"`
+	// 作者の X-ID (R18 only)
+	XID *string `json:"xid"`
 	// based on (not used)
 	Gensaku *string `json:"gensaku"`
 	// Keyword (space separated)
@@ -229,12 +308,20 @@ func (nt *novelTime) UnmarshalJSON(b []byte) (err error) {
 	if s == "null" {
 		return
 	}
+	nt.Time, err = time.ParseInLocation(novelTimeFormat, s, jst)
+	return
+}
+
+// jst is Asia/Tokyo, time of narou is in it
+var jst = loadJST()
+
+// loadJST returns fixed +09:00 zone if tzdata is not available, Japan has no DST
+func loadJST() *time.Location {
 	loc, err := time.LoadLocation("Asia/Tokyo")
 	if err != nil {
-		return
+		return time.FixedZone("Asia/Tokyo", 9*60*60)
 	}
-	nt.Time, err = time.ParseInLocation(novelTimeFormat, s, loc)
-	return
+	return loc
 }
 
 func intp(val int) *int       { return &val }
@@ -0,0 +1,205 @@
+package narrow
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"time"
+)
+
+// ErrTooManyResults means SearchAll can not split the query any more to get past the offset ceiling
+var ErrTooManyResults = errors.New("too many results to split into lastup windows")
+
+// narouEpoch is lower bound of `general_lastup`, before syosetu.com opened
+var narouEpoch = time.Date(2004, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// SearchAll returns iterator over every novel that params matched.
+//
+// It walks pages of `lim` (default 500) ignoring `st`, and once the result count
+// exceeds what `st` can reach, splits the query into `lastup` time windows
+// so that each novel is yielded exactly once. Relative `lastup` such as `thisweek`
+// is turned into JST window at the time of splitting. Novels are yielded window by window,
+// so `order` holds only within a window.
+// Iteration stops after the first error.
+func (c *Client) SearchAll(ctx context.Context, params Params) iter.Seq2[NovelInfo, error] {
+	return func(yield func(NovelInfo, error) bool) {
+		p, ok := params.(searchAllParams)
+		if !ok {
+			res, err := c.Search(ctx, params)
+			if err != nil {
+				yield(NovelInfo{}, err)
+				return
+			}
+			for _, info := range res.NovelInfos {
+				if !yield(info, nil) {
+					return
+				}
+			}
+			return
+		}
+
+		w := &searchAllWalker{c: c, params: p.clone(), seen: make(map[string]bool), yield: yield}
+		w.run(ctx)
+	}
+}
+
+// searchAllParams are Params which SearchAll can split
+type searchAllParams interface {
+	Params
+	base() *SearchParams
+	clone() searchAllParams
+}
+
+func (params *SearchParams) base() *SearchParams { return params }
+
+func (params *SearchParams) clone() searchAllParams { return params.copy() }
+
+func (params *SearchR18Params) clone() searchAllParams {
+	cp := &SearchR18Params{SearchParams: *params.SearchParams.copy()}
+	cp.nocGenres = append([]NocGenre(nil), params.nocGenres...)
+	cp.notNocGenres = append([]NocGenre(nil), params.notNocGenres...)
+	cp.xids = append([]string(nil), params.xids...)
+	return cp
+}
+
+type lastUpWindow struct {
+	start, end time.Time
+}
+
+type searchAllWalker struct {
+	c        *Client
+	params   searchAllParams
+	pageSize int
+	seen     map[string]bool
+	yield    func(NovelInfo, error) bool
+	stopped  bool
+}
+
+func (w *searchAllWalker) run(ctx context.Context) {
+	b := w.params.base()
+	w.pageSize = b.limit
+	if w.pageSize == 0 {
+		w.pageSize = maxLimit
+	}
+	b.limit = w.pageSize
+	b.offset = 0
+	// ncode is needed to drop duplicates between overlapping pages
+	if len(b.outputFields) != 0 {
+		b.AddOutputFields([]OutputField{OutputFieldNCode})
+	}
+
+	windows := []*lastUpWindow{nil}
+	for len(windows) > 0 && !w.stopped {
+		win := windows[0]
+		windows = windows[1:]
+
+		overflow, err := w.walkWindow(ctx, win)
+		if err != nil {
+			w.emit(NovelInfo{}, err)
+			return
+		}
+		if !overflow {
+			continue
+		}
+
+		if win == nil {
+			if win = w.fullWindow(b); win == nil {
+				w.emit(NovelInfo{}, ErrTooManyResults)
+				return
+			}
+		}
+		if win.end.Sub(win.start) < time.Second {
+			w.emit(NovelInfo{}, ErrTooManyResults)
+			return
+		}
+		mid := win.start.Add(win.end.Sub(win.start) / 2).Truncate(time.Second)
+		windows = append([]*lastUpWindow{
+			{start: win.start, end: mid},
+			{start: mid.Add(time.Second), end: win.end},
+		}, windows...)
+	}
+}
+
+// fullWindow returns lastup window covering whole condition of b, nil if unknown
+func (w *searchAllWalker) fullWindow(b *SearchParams) *lastUpWindow {
+	now := w.c.now().Truncate(time.Second)
+	switch b.lastUp {
+	case LastUpTypeNone:
+		return &lastUpWindow{start: narouEpoch, end: now}
+	case LastUpTypeTimeStamp:
+		return &lastUpWindow{start: b.lastUpStart, end: b.lastUpEnd}
+	}
+	return lastUpTermWindow(b.lastUp, now)
+}
+
+// lastUpTermWindow converts relative `lastup` such as `thisweek` into window in JST like api does,
+// weeks start on Sunday 0:00 and `sevenday` starts on 0:00 of 7 days ago
+func lastUpTermWindow(ltype LastUpType, now time.Time) *lastUpWindow {
+	now = now.In(jst)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, jst)
+	thisWeek := today.AddDate(0, 0, -int(today.Weekday()))
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, jst)
+	switch ltype {
+	case LastUpTypeThisWeek:
+		return &lastUpWindow{start: thisWeek, end: now}
+	case LastUpTypeLastWeek:
+		return &lastUpWindow{start: thisWeek.AddDate(0, 0, -7), end: thisWeek.Add(-time.Second)}
+	case LastUpTypeSevenDay:
+		return &lastUpWindow{start: today.AddDate(0, 0, -7), end: now}
+	case LastUpTypeThisMonth:
+		return &lastUpWindow{start: thisMonth, end: now}
+	case LastUpTypeLastMonth:
+		return &lastUpWindow{start: thisMonth.AddDate(0, -1, 0), end: thisMonth.Add(-time.Second)}
+	}
+	return nil
+}
+
+// walkWindow yields every novel in window, or returns overflow without yielding
+// when `st` can not reach all of them
+func (w *searchAllWalker) walkWindow(ctx context.Context, win *lastUpWindow) (bool, error) {
+	p := w.params.clone()
+	if win != nil {
+		p.base().SetLastUpTerm(win.start, win.end)
+	}
+	reachable := maxOffset + w.pageSize - 1
+
+	for st := minOffset; !w.stopped; st += w.pageSize {
+		if st > maxOffset {
+			// last page overlaps previous one, duplicates are dropped by seen
+			st = maxOffset
+		}
+		p.base().offset = st
+		res, err := w.c.Search(ctx, p)
+		if err != nil {
+			return false, err
+		}
+		if st == minOffset && res.AllCount > reachable {
+			return true, nil
+		}
+		for _, info := range res.NovelInfos {
+			if !w.emit(info, nil) {
+				return false, nil
+			}
+		}
+		if st+w.pageSize > res.AllCount || st == maxOffset || len(res.NovelInfos) == 0 {
+			break
+		}
+	}
+	return false, nil
+}
+
+func (w *searchAllWalker) emit(info NovelInfo, err error) bool {
+	if w.stopped {
+		return false
+	}
+	if err == nil && info.NCode != nil {
+		if w.seen[*info.NCode] {
+			return true
+		}
+		w.seen[*info.NCode] = true
+	}
+	if !w.yield(info, err) || err != nil {
+		w.stopped = true
+	}
+	return !w.stopped
+}
@@ -0,0 +1,205 @@
+package narrow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func textResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(bytes.NewBufferString(body)),
+	}
+}
+
+// fakeNovelAPI serves novels ordered by lastup desc, honoring lastup, st and lim like novel api
+func fakeNovelAPI(t *testing.T, lastUps []time.Time, requests *int) roundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		*requests++
+		q := req.URL.Query()
+		st, lim := 1, 20
+		if v := q.Get("st"); v != "" {
+			st, _ = strconv.Atoi(v)
+		}
+		if v := q.Get("lim"); v != "" {
+			lim, _ = strconv.Atoi(v)
+		}
+		if st < minOffset || st > maxOffset || lim < minLimit || lim > maxLimit {
+			t.Errorf("request out of range st=%d lim=%d", st, lim)
+			return textResponse(http.StatusBadRequest, "bad request"), nil
+		}
+
+		type novel struct {
+			ncode  string
+			lastUp time.Time
+		}
+		var matched []novel
+		start, end := int64(0), int64(1<<62)
+		if v := q.Get("lastup"); v != "" {
+			se := strings.Split(v, "-")
+			start, _ = strconv.ParseInt(se[0], 10, 64)
+			end, _ = strconv.ParseInt(se[1], 10, 64)
+		}
+		for i, lu := range lastUps {
+			if lu.Unix() >= start && lu.Unix() <= end {
+				matched = append(matched, novel{fmt.Sprintf("N%04dA", i), lu})
+			}
+		}
+		sort.Slice(matched, func(i, j int) bool { return matched[i].lastUp.After(matched[j].lastUp) })
+
+		res := []map[string]interface{}{{"allcount": len(matched)}}
+		for i := st - 1; i < st-1+lim && i < len(matched); i++ {
+			res = append(res, map[string]interface{}{"ncode": matched[i].ncode})
+		}
+		body, _ := json.Marshal(res)
+		return textResponse(http.StatusOK, string(body)), nil
+	}
+}
+
+func TestClient_SearchAll(t *testing.T) {
+	base := time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		count int
+		limit int
+	}{
+		{"within a page", 30, 0},
+		{"within offset ceiling", 2499, 0},
+		{"over offset ceiling", 6000, 0},
+		{"over offset ceiling, small page", 3000, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lastUps := make([]time.Time, tt.count)
+			for i := range lastUps {
+				// several novels share the same second
+				lastUps[i] = base.Add(time.Duration(i/3) * time.Minute)
+			}
+			requests := 0
+			c := &Client{httpClient: &http.Client{Transport: fakeNovelAPI(t, lastUps, &requests)}}
+
+			params := NewSearchParams()
+			if tt.limit != 0 {
+				params.SetLimit(tt.limit)
+			}
+			got := make(map[string]int)
+			for info, err := range c.SearchAll(context.Background(), params) {
+				if err != nil {
+					t.Fatalf("Client.SearchAll() error = %v", err)
+				}
+				got[*info.NCode]++
+			}
+			if len(got) != tt.count {
+				t.Errorf("Client.SearchAll() returns %d novels, want %d", len(got), tt.count)
+			}
+			for ncode, n := range got {
+				if n != 1 {
+					t.Errorf("Client.SearchAll() returns %s %d times", ncode, n)
+				}
+			}
+			if params.Start() != 0 || params.LastUpType() != LastUpTypeNone {
+				t.Errorf("Client.SearchAll() should not change params, %+v", params)
+			}
+		})
+	}
+}
+
+func TestClient_SearchAll_break(t *testing.T) {
+	lastUps := make([]time.Time, 1000)
+	for i := range lastUps {
+		lastUps[i] = time.Unix(int64(1500000000+i), 0)
+	}
+	requests := 0
+	c := &Client{httpClient: &http.Client{Transport: fakeNovelAPI(t, lastUps, &requests)}}
+
+	n := 0
+	for range c.SearchAll(context.Background(), NewSearchParams()) {
+		n++
+		if n == 10 {
+			break
+		}
+	}
+	if n != 10 || requests != 1 {
+		t.Errorf("Client.SearchAll() break after %d novels, %d requests", n, requests)
+	}
+}
+
+func TestClient_SearchAll_lastUpTerm(t *testing.T) {
+	// wednesday, this week started on 2019-08-04 sunday in JST
+	clock := &fakeClock{time.Date(2019, 8, 7, 12, 0, 0, 0, jst)}
+	weekStart := time.Date(2019, 8, 4, 0, 0, 0, 0, jst)
+	lastUps := make([]time.Time, 3500)
+	for i := range lastUps {
+		// 3000 novels this week, 500 before it
+		lastUps[i] = weekStart.Add(time.Duration(i-500) * 20 * time.Second)
+	}
+	requests := 0
+	api := fakeNovelAPI(t, lastUps, &requests)
+	c := &Client{clock: clock.now, httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		// fakeNovelAPI knows only timestamps
+		if q := req.URL.Query(); q.Get("lastup") == "thisweek" {
+			q.Set("lastup", fmt.Sprintf("%d-%d", weekStart.Unix(), clock.t.Unix()))
+			req.URL.RawQuery = q.Encode()
+		}
+		return api(req)
+	})}}
+
+	params := NewSearchParams()
+	params.SetLastUp(LastUpTypeThisWeek)
+	got := make(map[string]int)
+	for info, err := range c.SearchAll(context.Background(), params) {
+		if err != nil {
+			t.Fatalf("Client.SearchAll() error = %v", err)
+		}
+		got[*info.NCode]++
+	}
+	if len(got) != 3000 {
+		t.Errorf("Client.SearchAll() returns %d novels, want 3000", len(got))
+	}
+	for ncode, n := range got {
+		if i, _ := strconv.Atoi(ncode[1:5]); i < 500 || n != 1 {
+			t.Errorf("Client.SearchAll() returns %s %d times", ncode, n)
+		}
+	}
+	if params.LastUpType() != LastUpTypeThisWeek {
+		t.Errorf("Client.SearchAll() should not change params, %+v", params)
+	}
+}
+
+func Test_lastUpTermWindow(t *testing.T) {
+	// wednesday 2019-08-07 in JST, still tuesday in UTC
+	now := time.Date(2019, 8, 6, 15, 30, 0, 0, time.UTC)
+	tests := []struct {
+		ltype      LastUpType
+		start, end time.Time
+	}{
+		{LastUpTypeThisWeek, time.Date(2019, 8, 4, 0, 0, 0, 0, jst), now},
+		{LastUpTypeLastWeek, time.Date(2019, 7, 28, 0, 0, 0, 0, jst), time.Date(2019, 8, 3, 23, 59, 59, 0, jst)},
+		{LastUpTypeSevenDay, time.Date(2019, 7, 31, 0, 0, 0, 0, jst), now},
+		{LastUpTypeThisMonth, time.Date(2019, 8, 1, 0, 0, 0, 0, jst), now},
+		{LastUpTypeLastMonth, time.Date(2019, 7, 1, 0, 0, 0, 0, jst), time.Date(2019, 7, 31, 23, 59, 59, 0, jst)},
+	}
+	for _, tt := range tests {
+		got := lastUpTermWindow(tt.ltype, now)
+		if got == nil || !got.start.Equal(tt.start) || !got.end.Equal(tt.end) {
+			t.Errorf("lastUpTermWindow(%v) = %+v, want %v - %v", tt.ltype, got, tt.start, tt.end)
+		}
+	}
+	if got := lastUpTermWindow(LastUpTypeTimeStamp, now); got != nil {
+		t.Errorf("lastUpTermWindow(LastUpTypeTimeStamp) = %+v, want nil", got)
+	}
+}
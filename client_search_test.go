@@ -1,8 +1,13 @@
 package narrow
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"reflect"
 	"testing"
 	"time"
@@ -148,6 +153,19 @@ func Test_parseResponse(t *testing.T) {
 			&SearchResult{AllCount: 123, NovelInfos: []NovelInfo{{NovelType: intp(1)}, {NovelType: intp(2)}}},
 			false,
 		},
+		{"r18 fields",
+			args{[]byte(`[{"allcount": 1},{"ncode":"N1111XX","nocgenre":3,"xid":"x1234a"}]`)},
+			&SearchResult{AllCount: 1, NovelInfos: []NovelInfo{{NCode: strp("N1111XX"), NocturneGenre: intp(3), XID: strp("x1234a"), fromSearchX: true}}},
+			false,
+		},
+		{"no result",
+			args{[]byte(`[{"allcount": 0}]`)},
+			&SearchResult{AllCount: 0, NovelInfos: []NovelInfo{}},
+			false,
+		},
+		{"empty array", args{[]byte(`[]`)}, nil, true},
+		{"error document", args{[]byte(`{"error":"invalid parameter"}`)}, nil, true},
+		{"html", args{[]byte(`<html><body>error</body></html>`)}, nil, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -172,11 +190,90 @@ func Test_parseResponse(t *testing.T) {
 	}
 }
 
-func jstDate(year int, month time.Month, day, hour, min, sec, nsec int) *time.Time {
-	jst, err := time.LoadLocation("Asia/Tokyo")
+func Test_parseResponse_gzip(t *testing.T) {
+	plain := []byte(`[{"allcount": 123},{"title":"AAA"}, {"title":"BBB"}]`)
+	var buf bytes.Buffer
+	w, _ := gzip.NewWriterLevel(&buf, 5)
+	w.Write(plain)
+	w.Close()
+
+	want := &SearchResult{AllCount: 123, NovelInfos: []NovelInfo{{Title: strp("AAA")}, {Title: strp("BBB")}}}
+	got, err := parseSearchResponse(buf.Bytes())
 	if err != nil {
-		return nil
+		t.Errorf("parseResponse() error = %v", err)
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseResponse() = %+v, want %+v", got, want)
 	}
+}
+
+func Test_parseResponse_apiError(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr error
+		wantMsg string
+	}{
+		{"empty array", `[]`, ErrEmptyResponse, "[]"},
+		{"no allcount", `[{"title":"AAA"}]`, ErrEmptyResponse, `[{"title":"AAA"}]`},
+		{"error document", `{"error":"invalid parameter"}`, nil, "invalid parameter"},
+		{"html", "<html><body><p>error\n occurred</p></body></html>", nil, "error occurred"},
+		{"maintenance", `<html><body>ただいまメンテナンス中です</body></html>`, ErrMaintenance, "ただいまメンテナンス中です"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseSearchResponse([]byte(tt.body))
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Errorf("parseResponse() error = %v, want *APIError", err)
+				return
+			}
+			if apiErr.Err != tt.wantErr || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Errorf("parseResponse() error = %v, want %v", apiErr.Err, tt.wantErr)
+			}
+			if apiErr.Message != tt.wantMsg {
+				t.Errorf("parseResponse() message = %q, want %q", apiErr.Message, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestClient_Search_apiError(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantStatus int
+		wantErr    error
+	}{
+		{"rate limited", http.StatusTooManyRequests, "too many requests", 429, ErrRateLimited},
+		{"maintenance", http.StatusServiceUnavailable, "<html>maintenance</html>", 503, ErrMaintenance},
+		{"bad request", http.StatusBadRequest, `{"error":"bad"}`, 400, nil},
+		{"empty", http.StatusOK, `[]`, 200, ErrEmptyResponse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				return textResponse(tt.status, tt.body), nil
+			})}}
+			_, err := c.Search(context.Background(), NewSearchParams())
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Errorf("Client.Search() error = %v, want *APIError", err)
+				return
+			}
+			if apiErr.StatusCode != tt.wantStatus || apiErr.URL != NarouAPIEndPoint+"?out=json" {
+				t.Errorf("Client.Search() error = %+v", apiErr)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Client.Search() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func jstDate(year int, month time.Month, day, hour, min, sec, nsec int) *time.Time {
 	t := time.Date(year, month, day, hour, min, sec, nsec, jst)
 	return &t
 }
@@ -0,0 +1,56 @@
+package narrow
+
+import "context"
+
+// UserInfo is a user (author) returned by user api
+type UserInfo struct {
+	// ユーザID
+	UserID int `json:"userid"`
+	// ユーザ名
+	Name string `json:"name"`
+	// 読み仮名
+	Yomikata string `json:"yomikata"`
+	// 読み仮名の頭文字のカタカナ (ア〜ワ), それ以外は `その他`
+	Name1st string `json:"name1st"`
+	// 小説投稿数
+	NovelCount int `json:"novel_cnt"`
+	// レビュー投稿数
+	ReviewCount int `json:"review_cnt"`
+	// 小説累計文字数, 短編と非公開を除く
+	NovelLength int `json:"novel_length"`
+	// 総合評価ポイントの合計
+	SumGlobalPoint int `json:"sum_global_point"`
+}
+
+// UserSearchResult contains user api result
+type UserSearchResult struct {
+	AllCount int
+	Users    []UserInfo
+}
+
+// userSearchResponse is an element of user api response, first element has only allcount
+type userSearchResponse struct {
+	AllCount *int `json:"allcount"`
+	UserInfo
+}
+
+// SearchUsers searches users (authors) with params
+func (c *Client) SearchUsers(ctx context.Context, params *UserSearchParams) (*UserSearchResult, error) {
+	u, err := params.ToURL()
+	if err != nil {
+		return nil, err
+	}
+	var responses []userSearchResponse
+	if err := c.getAPIJSON(ctx, u, &responses); err != nil {
+		return nil, err
+	}
+	if len(responses) == 0 || responses[0].AllCount == nil {
+		return nil, &APIError{URL: u.String(), Err: ErrEmptyResponse}
+	}
+
+	res := &UserSearchResult{AllCount: *responses[0].AllCount}
+	for _, r := range responses[1:] {
+		res.Users = append(res.Users, r.UserInfo)
+	}
+	return res, nil
+}
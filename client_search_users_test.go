@@ -0,0 +1,41 @@
+package narrow
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestClient_SearchUsers(t *testing.T) {
+	body := `[{"allcount":2},
+{"userid":1,"name":"作者","yomikata":"サクシャ","name1st":"サ","novel_cnt":3,"review_cnt":1,"novel_length":12345,"sum_global_point":678},
+{"userid":2,"name":"author","yomikata":"","name1st":"その他","novel_cnt":0,"review_cnt":0,"novel_length":0,"sum_global_point":0}]`
+	var query string
+	c := &Client{httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/userapi/api/" {
+			return textResponse(http.StatusNotFound, "not found"), nil
+		}
+		query = req.URL.RawQuery
+		return textResponse(http.StatusOK, body), nil
+	})}}
+
+	params := NewUserSearchParams()
+	params.SetMinNovel(1)
+	res, err := c.SearchUsers(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Client.SearchUsers() error = %v", err)
+	}
+	if query != "minnovel=1&out=json" {
+		t.Errorf("Client.SearchUsers() query = %s", query)
+	}
+	if res.AllCount != 2 || len(res.Users) != 2 {
+		t.Fatalf("Client.SearchUsers() = %+v", res)
+	}
+	want := UserInfo{UserID: 1, Name: "作者", Yomikata: "サクシャ", Name1st: "サ", NovelCount: 3, ReviewCount: 1, NovelLength: 12345, SumGlobalPoint: 678}
+	if res.Users[0] != want {
+		t.Errorf("Client.SearchUsers() Users[0] = %+v, want %+v", res.Users[0], want)
+	}
+	if res.Users[1].Name1st != "その他" {
+		t.Errorf("Client.SearchUsers() Users[1] = %+v", res.Users[1])
+	}
+}
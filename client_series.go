@@ -0,0 +1,134 @@
+package narrow
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Series contains series (シリーズ) page contents
+type Series struct {
+	Site FetchSite
+	// SCode is series code such as `s1234a`
+	SCode string
+	// シリーズ名
+	Title string
+	// シリーズ説明
+	Description string
+	// NCodes is member novels in series order
+	NCodes []string
+}
+
+var memberNCodeRe = regexp.MustCompile(`^/(n[0-9]{4}[a-z]{1,2})/?$`)
+
+// FetchSeries downloads series page `<base>/<scode>/`, following its pager
+func (c *Client) FetchSeries(ctx context.Context, site FetchSite, scode string) (*Series, error) {
+	// series page is `<base>/<scode>/` like novel index
+	params := &FetchParams{Site: site, NCode: strings.ToLower(scode), AllowOver18: true}
+	u, err := params.toContentURL(c.contentBase(site))
+	if err != nil {
+		return nil, err
+	}
+	ctx = allowOver18(ctx, params)
+
+	series := &Series{Site: site, SCode: params.NCode}
+	seen := make(map[string]bool)
+	visited := make(map[string]bool)
+	for n := 0; !visited[u.String()] && n < maxIndexPages; n++ {
+		visited[u.String()] = true
+		status, body, fromCache, err := c.getBody(ctx, endpointIndex, u)
+		if err != nil {
+			return nil, err
+		}
+		if status != http.StatusOK {
+			return nil, fmt.Errorf("fetch series %s: status %d", u, status)
+		}
+		part, next, err := parseSeries(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if !fromCache {
+			c.storeCache(endpointIndex, u.String(), body)
+		}
+
+		if series.Title == "" {
+			series.Title = part.Title
+			series.Description = part.Description
+		}
+		for _, ncode := range part.NCodes {
+			if !seen[ncode] {
+				seen[ncode] = true
+				series.NCodes = append(series.NCodes, ncode)
+			}
+		}
+
+		if next == "" {
+			break
+		}
+		ref, err := url.Parse(next)
+		if err != nil {
+			return nil, err
+		}
+		u = u.ResolveReference(ref)
+	}
+	return series, nil
+}
+
+// FetchSeriesNovels fetches every member novel of series with params, params.Site and NCode are replaced.
+// Failure of a novel does not stop others, results are in series order and nil for novels failed before index
+func (c *Client) FetchSeriesNovels(ctx context.Context, series *Series, params *FetchParams) ([]*FetchResult, error) {
+	results := make([]*FetchResult, len(series.NCodes))
+	var errs []error
+	for i, ncode := range series.NCodes {
+		p := *params
+		p.Site = series.Site
+		p.NCode = ncode
+		res, err := c.Fetch(ctx, &p)
+		results[i] = res
+		if err != nil {
+			errs = append(errs, fmt.Errorf("fetch %s: %w", ncode, err))
+			if ctx.Err() != nil {
+				break
+			}
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// parseSeries reads series title, description and member ncodes, next is href of next page or empty
+func parseSeries(r io.Reader) (*Series, string, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, "", err
+	}
+	series := &Series{}
+	series.Title = strings.TrimSpace(doc.Find(".p-series__title, .series_title, h1").First().Text())
+	series.Description = strings.TrimSpace(doc.Find(".p-series__summary, #series_ex, .series_ex").First().Text())
+
+	// links outside the list, such as header or recommendations, are not members
+	list := doc.Find(".p-series-novellist, .serieslist, .series_novelist")
+	if list.Size() == 0 {
+		return nil, "", errors.New("series novel list not found")
+	}
+	list.Find("a[href]").Each(func(_ int, a *goquery.Selection) {
+		href, _ := a.Attr("href")
+		ref, err := url.Parse(href)
+		if err != nil {
+			return
+		}
+		if m := memberNCodeRe.FindStringSubmatch(strings.ToLower(ref.Path)); m != nil {
+			series.NCodes = append(series.NCodes, m[1])
+		}
+	})
+
+	next, _ := doc.Find(".c-pager a.c-pager__item--next").First().Attr("href")
+	return series, next, nil
+}
@@ -0,0 +1,88 @@
+package narrow
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClient_FetchSeries(t *testing.T) {
+	page2 := `<html><body><div class="p-series">
+<h1 class="p-series__title">古典随筆集</h1>
+<div class="p-series-novellist">
+<div class="p-series-novellist__item"><a class="p-series-novellist__title" href="/n2222cc/">枕草子</a></div>
+</div>
+<div class="c-pager"><span class="c-pager__item c-pager__item--next">次へ</span></div>
+</div></body></html>`
+	legacy := fakeContentSite(map[string]string{"/s1234a/": readTestData(t, "legacy_series.html")}, nil)
+	current := fakeContentSite(map[string]string{"/s1234a/": readTestData(t, "current_series.html")}, nil)
+
+	tests := []struct {
+		name      string
+		transport roundTripFunc
+		want      *Series
+	}{
+		{"legacy", legacy, &Series{
+			SCode: "s1234a", Title: "古典随筆集", Description: "日本の古典随筆をまとめたシリーズです。",
+			NCodes: []string{"n0000aa", "n1111bb", "n2222cc"},
+		}},
+		{"current paginated", func(req *http.Request) (*http.Response, error) {
+			if req.URL.Query().Get("p") == "2" {
+				return textResponse(http.StatusOK, page2), nil
+			}
+			return current(req)
+		}, &Series{
+			SCode: "s1234a", Title: "古典随筆集", Description: "日本の古典随筆をまとめたシリーズです。",
+			NCodes: []string{"n0000aa", "n1111bb", "n2222cc"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{httpClient: &http.Client{Transport: tt.transport}}
+			got, err := c.FetchSeries(context.Background(), FetchSiteNarou, "S1234A")
+			if err != nil {
+				t.Fatalf("Client.FetchSeries() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Client.FetchSeries() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseSeries_noList(t *testing.T) {
+	html := `<html><body><h1>シリーズ</h1><a href="/n0000aa/">おすすめ</a></body></html>`
+	if series, _, err := parseSeries(strings.NewReader(html)); err == nil {
+		t.Errorf("parseSeries() = %+v, want error for page without novel list", series)
+	}
+}
+
+func TestClient_FetchSeriesNovels(t *testing.T) {
+	var requests []string
+	site := fakeContentSite(map[string]string{
+		"/n0000aa/": readTestData(t, "legacy_index.html"),
+		"/n2222cc/": readTestData(t, "legacy_short.html"),
+	}, &requests)
+	c := &Client{httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/n1111bb/" {
+			requests = append(requests, req.URL.Path)
+			return nil, errors.New("broken")
+		}
+		return site(req)
+	})}}
+	series := &Series{SCode: "s1234a", NCodes: []string{"n0000aa", "n1111bb", "n2222cc"}}
+
+	got, err := c.FetchSeriesNovels(context.Background(), series, &FetchParams{NCode: "ignored"})
+	if err == nil {
+		t.Errorf("Client.FetchSeriesNovels() error = nil, want error of n1111bb")
+	}
+	if want := []string{"/n0000aa/", "/n1111bb/", "/n2222cc/"}; !reflect.DeepEqual(requests, want) {
+		t.Errorf("Client.FetchSeriesNovels() requests = %v, want %v", requests, want)
+	}
+	if len(got) != 3 || got[0] == nil || got[0].NCode != "n0000aa" || got[2] == nil || got[2].NovelType != 2 {
+		t.Errorf("Client.FetchSeriesNovels() = %+v", got)
+	}
+}
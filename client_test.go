@@ -0,0 +1,117 @@
+package narrow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewClient(t *testing.T) {
+	hc := &http.Client{}
+	c := NewClient(
+		WithHTTPClient(hc),
+		WithUserAgent("mycrawler/1.0 (+mailto:me@example.com)"),
+		WithAPIBaseURL("http://localhost/novelapi/"),
+		WithR18APIBaseURL("http://localhost/novel18api/"),
+		WithContentBaseURL("http://localhost/ncode/"),
+		WithR18ContentBaseURL("http://localhost/novel18/"),
+	)
+	if c.httpClient != hc {
+		t.Errorf("NewClient() httpClient = %v, want %v", c.httpClient, hc)
+	}
+	if c.userAgent != "mycrawler/1.0 (+mailto:me@example.com)" {
+		t.Errorf("NewClient() userAgent = %v", c.userAgent)
+	}
+	if c.contentBase(FetchSiteNarou) != "http://localhost/ncode/" || c.contentBase(FetchSiteNocturne) != "http://localhost/novel18/" {
+		t.Errorf("NewClient() content base = %v, %v", c.contentBase(FetchSiteNarou), c.contentBase(FetchSiteNocturne))
+	}
+
+	d := NewClient()
+	if d.userAgent != userAgent || d.contentBase(FetchSiteNarou) != NarouContentBaseURL {
+		t.Errorf("NewClient() default = %+v", d)
+	}
+}
+
+func TestClient_rebaseAPIURL(t *testing.T) {
+	c := NewClient(WithAPIBaseURL("http://127.0.0.1:8080/proxy/novelapi"))
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"novel api", "https://api.syosetu.com/novelapi/api/?out=json&lim=1", "http://127.0.0.1:8080/proxy/novelapi?out=json&lim=1"},
+		{"r18 api as is", "https://api.syosetu.com/novel18api/api/?out=json", "https://api.syosetu.com/novel18api/api/?out=json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.rebaseAPIURL(parseURL(tt.url))
+			if err != nil {
+				t.Errorf("Client.rebaseAPIURL() error = %v", err)
+				return
+			}
+			if got.String() != tt.want {
+				t.Errorf("Client.rebaseAPIURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_withBaseURL(t *testing.T) {
+	var gotPaths, gotUAs, gotCookies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPaths = append(gotPaths, r.URL.Path)
+		gotUAs = append(gotUAs, r.UserAgent())
+		gotCookies = append(gotCookies, r.Header.Get("Cookie"))
+		switch r.URL.Path {
+		case "/novelapi/api/", "/novel18api/api/":
+			w.Write([]byte(`[{"allcount":1},{"title":"AAA"}]`))
+		default:
+			w.Write([]byte(`<html><head><title>short</title></head><body><div id="novel_color"><div id="novel_honbun"><p id="L1">line</p></div></div></body></html>`))
+		}
+	}))
+	defer ts.Close()
+
+	hc := ts.Client()
+	c := NewClient(
+		WithHTTPClient(hc),
+		WithUserAgent("test-agent"),
+		WithAPIBaseURL(ts.URL+"/novelapi/api/"),
+		WithR18APIBaseURL(ts.URL+"/novel18api/api/"),
+		WithContentBaseURL(ts.URL+"/ncode/"),
+		WithR18ContentBaseURL(ts.URL+"/novel18/"),
+		WithContentRateLimit(RateLimit{}),
+	)
+	ctx := context.Background()
+	if _, err := c.Search(ctx, NewSearchParams()); err != nil {
+		t.Errorf("Client.Search() error = %v", err)
+	}
+	if _, err := c.Search(ctx, NewSearchR18Params()); err != nil {
+		t.Errorf("Client.Search() error = %v", err)
+	}
+	res, err := c.Fetch(ctx, &FetchParams{Site: FetchSiteNocturne, NCode: "n0000a", AllowOver18: true})
+	if err != nil {
+		t.Errorf("Client.Fetch() error = %v", err)
+	} else if res.PageCount != 1 || len(res.Pages[0].Lines) != 1 {
+		t.Errorf("Client.Fetch() = %+v", res)
+	}
+
+	wantPaths := []string{"/novelapi/api/", "/novel18api/api/", "/novel18/n0000a/"}
+	for i, want := range wantPaths {
+		if i >= len(gotPaths) || gotPaths[i] != want {
+			t.Errorf("request paths = %v, want %v", gotPaths, wantPaths)
+			break
+		}
+	}
+	for _, ua := range gotUAs {
+		if ua != "test-agent" {
+			t.Errorf("user-agent = %v, want test-agent", ua)
+		}
+	}
+	if len(gotCookies) != 3 || gotCookies[2] != "over18=yes" {
+		t.Errorf("cookies = %q, want over18=yes for R18 content", gotCookies)
+	}
+	if hc.Jar != nil {
+		t.Errorf("http.Client of WithHTTPClient got cookie jar %v", hc.Jar)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/t-ashula/go-narrow"
 
@@ -50,16 +51,31 @@ func fetchCommand() cli.Command {
 			cli.BoolFlag{
 				Name: "with-all",
 			},
+			cli.StringFlag{
+				Name:  "pages",
+				Usage: "fetch pages in `RANGES` such as 1,3,120-180,200-",
+			},
+			cli.IntFlag{
+				Name:  "last",
+				Usage: "fetch latest `N` pages",
+			},
+			cli.StringFlag{
+				Name:  "since",
+				Usage: "fetch pages published or revised since `DATE` (YYYY-MM-DD, JST)",
+			},
 		},
 		Action: func(c *cli.Context) error {
-			params := makeFetchParams(c)
-			client := narrow.NewClient()
-			res, err := client.Fetch(context.Background(), params)
+			params, err := makeFetchParams(c)
 			if err != nil {
 				return err
 			}
-			fmt.Printf("FetchResult:%+v\n", res)
-			return nil
+			client := narrow.NewClient()
+			res, err := client.Fetch(context.Background(), params)
+			if res != nil {
+				// partial result on page failures
+				fmt.Printf("FetchResult:%+v\n", res)
+			}
+			return err
 		},
 	}
 }
@@ -155,7 +171,7 @@ func nocgenre(site string) narrow.NocGenre {
 	}
 }
 
-func makeFetchParams(c *cli.Context) *narrow.FetchParams {
+func makeFetchParams(c *cli.Context) (*narrow.FetchParams, error) {
 	params := narrow.NewFetchParams()
 	site := c.String("site")
 	switch site {
@@ -174,5 +190,29 @@ func makeFetchParams(c *cli.Context) *narrow.FetchParams {
 	params.AllowOver18 = c.Bool("over18")
 	params.Page = c.Int("page")
 	params.WithContent = c.Bool("with-all")
-	return params
+
+	if c.String("pages") == "" && c.Int("last") == 0 && c.String("since") == "" {
+		return params, nil
+	}
+	sel := &narrow.PageSelection{Last: c.Int("last")}
+	if pages := c.String("pages"); pages != "" {
+		ranges, err := narrow.ParsePageRanges(pages)
+		if err != nil {
+			return nil, err
+		}
+		sel.Ranges = ranges
+	}
+	if since := c.String("since"); since != "" {
+		loc, err := time.LoadLocation("Asia/Tokyo")
+		if err != nil {
+			return nil, err
+		}
+		t, err := time.ParseInLocation("2006-01-02", since, loc)
+		if err != nil {
+			return nil, err
+		}
+		sel.Since = &t
+	}
+	params.Pages = sel
+	return params, nil
 }
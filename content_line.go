@@ -0,0 +1,124 @@
+package narrow
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var lineIDRe = regexp.MustCompile(`^L[pa]?([0-9]+)$`)
+
+// emphasisDots are ruby readings narou uses for 傍点
+var emphasisDots = map[string]bool{"・": true, "﹅": true, "﹆": true, "●": true, "•": true}
+
+// parseContentLine builds ContentLine from `<p id="L42">` element
+func parseContentLine(s *goquery.Selection, raw string) ContentLine {
+	line := ContentLine{RawLine: raw}
+	if id, ok := s.Attr("id"); ok {
+		if m := lineIDRe.FindStringSubmatch(id); m != nil {
+			line.Number, _ = strconv.Atoi(m[1])
+		}
+	}
+
+	var segs segments
+	segs.appendNodes(s)
+	// `<p><br></p>` is empty line, not line break
+	if len(segs) == 1 && segs[0].Type == SegmentBreak {
+		segs = nil
+	}
+	line.Segments = segs
+
+	var text strings.Builder
+	for _, seg := range segs {
+		switch seg.Type {
+		case SegmentBreak:
+			text.WriteString("\n")
+		case SegmentIllustration:
+		default:
+			text.WriteString(seg.Text)
+		}
+	}
+	line.Text = text.String()
+	return line
+}
+
+type segments []Segment
+
+// add appends seg, merging it into previous text or emphasis segment
+func (segs *segments) add(seg Segment) {
+	if n := len(*segs); n > 0 && (seg.Type == SegmentText || seg.Type == SegmentEmphasis) {
+		last := &(*segs)[n-1]
+		if last.Type == seg.Type {
+			last.Text += seg.Text
+			return
+		}
+	}
+	if (seg.Type == SegmentText || seg.Type == SegmentEmphasis) && seg.Text == "" {
+		return
+	}
+	*segs = append(*segs, seg)
+}
+
+func (segs *segments) appendNodes(s *goquery.Selection) {
+	s.Contents().Each(func(_ int, node *goquery.Selection) {
+		switch goquery.NodeName(node) {
+		case "#text":
+			segs.add(Segment{Type: SegmentText, Text: node.Text()})
+		case "br":
+			segs.add(Segment{Type: SegmentBreak})
+		case "ruby":
+			segs.add(parseRuby(node))
+		case "em":
+			segs.add(Segment{Type: SegmentEmphasis, Text: node.Text()})
+		case "img":
+			segs.add(parseIllustration(node))
+		case "a":
+			if node.Find("img").Size() == 0 {
+				segs.appendNodes(node)
+				return
+			}
+			seg := parseIllustration(node.Find("img").First())
+			if href, ok := node.Attr("href"); ok {
+				seg.LinkURL = absoluteURL(href)
+			}
+			segs.add(seg)
+		case "rp", "#comment":
+		default:
+			segs.appendNodes(node)
+		}
+	})
+}
+
+// parseRuby reads `<ruby><rb>base</rb><rp>(</rp><rt>reading</rt><rp>)</rp></ruby>`, rb may be omitted
+func parseRuby(s *goquery.Selection) Segment {
+	var base, ruby strings.Builder
+	s.Contents().Each(func(_ int, node *goquery.Selection) {
+		switch goquery.NodeName(node) {
+		case "rt":
+			ruby.WriteString(node.Text())
+		case "rp", "#comment":
+		default:
+			base.WriteString(node.Text())
+		}
+	})
+	reading := strings.TrimSpace(ruby.String())
+	if emphasisDots[reading] {
+		return Segment{Type: SegmentEmphasis, Text: base.String()}
+	}
+	return Segment{Type: SegmentRuby, Text: base.String(), Ruby: reading}
+}
+
+func parseIllustration(img *goquery.Selection) Segment {
+	src, _ := img.Attr("src")
+	return Segment{Type: SegmentIllustration, ImageURL: absoluteURL(src)}
+}
+
+// absoluteURL makes protocol relative URL such as `//1234.mitemin.net/` https
+func absoluteURL(u string) string {
+	if strings.HasPrefix(u, "//") {
+		return "https:" + u
+	}
+	return u
+}
@@ -0,0 +1,73 @@
+package narrow
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func Test_parseContentLine(t *testing.T) {
+	tests := []struct {
+		name       string
+		html       string
+		wantNumber int
+		wantText   string
+		wantSegs   []Segment
+	}{
+		{"text", `<p id="L42">　ほげほげ</p>`, 42, "　ほげほげ",
+			[]Segment{{Type: SegmentText, Text: "　ほげほげ"}}},
+		{"preface id", `<p id="Lp3">前書き</p>`, 3, "前書き",
+			[]Segment{{Type: SegmentText, Text: "前書き"}}},
+		{"afterword id", `<p id="La1">後書き</p>`, 1, "後書き",
+			[]Segment{{Type: SegmentText, Text: "後書き"}}},
+		{"no id", `<p>text</p>`, 0, "text",
+			[]Segment{{Type: SegmentText, Text: "text"}}},
+		{"empty line", `<p id="L2"><br></p>`, 2, "", nil},
+		{"ruby",
+			`<p id="L1">日くらし、<ruby><rb>硯</rb><rp>(</rp><rt>すずり</rt><rp>)</rp></ruby>にむかひて</p>`, 1, "日くらし、硯にむかひて",
+			[]Segment{
+				{Type: SegmentText, Text: "日くらし、"},
+				{Type: SegmentRuby, Text: "硯", Ruby: "すずり"},
+				{Type: SegmentText, Text: "にむかひて"},
+			}},
+		{"ruby without rb", `<p id="L1"><ruby>漢字<rp>(</rp><rt>かんじ</rt><rp>)</rp></ruby></p>`, 1, "漢字",
+			[]Segment{{Type: SegmentRuby, Text: "漢字", Ruby: "かんじ"}}},
+		{"emphasis dots",
+			`<p id="L1">これは<ruby><rb>傍</rb><rp>(</rp><rt>・</rt><rp>)</rp></ruby><ruby><rb>点</rb><rp>(</rp><rt>・</rt><rp>)</rp></ruby>です</p>`, 1, "これは傍点です",
+			[]Segment{
+				{Type: SegmentText, Text: "これは"},
+				{Type: SegmentEmphasis, Text: "傍点"},
+				{Type: SegmentText, Text: "です"},
+			}},
+		{"emphasis em", `<p id="L1"><em class="emphasisDots"><span>傍点</span></em></p>`, 1, "傍点",
+			[]Segment{{Type: SegmentEmphasis, Text: "傍点"}}},
+		{"break", `<p id="L1">上<br>下</p>`, 1, "上\n下",
+			[]Segment{{Type: SegmentText, Text: "上"}, {Type: SegmentBreak}, {Type: SegmentText, Text: "下"}}},
+		{"illustration",
+			`<p id="L5"><a href="//1234.mitemin.net/i56789/" target="_blank"><img src="//1234.mitemin.net/userpageimage/viewimagebig/icode/i56789/" alt="挿絵(By みてみん)" border="0"></a></p>`, 5, "",
+			[]Segment{{
+				Type:     SegmentIllustration,
+				ImageURL: "https://1234.mitemin.net/userpageimage/viewimagebig/icode/i56789/",
+				LinkURL:  "https://1234.mitemin.net/i56789/",
+			}}},
+		{"nested span", `<p id="L1"><span>a</span>b</p>`, 1, "ab",
+			[]Segment{{Type: SegmentText, Text: "ab"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := parseContentLine(doc.Find("p").First(), tt.html)
+			if got.Number != tt.wantNumber || got.Text != tt.wantText || got.RawLine != tt.html {
+				t.Errorf("parseContentLine() = %d %q, want %d %q", got.Number, got.Text, tt.wantNumber, tt.wantText)
+			}
+			if !reflect.DeepEqual(got.Segments, tt.wantSegs) {
+				t.Errorf("parseContentLine() segments = %+v, want %+v", got.Segments, tt.wantSegs)
+			}
+		})
+	}
+}
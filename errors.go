@@ -0,0 +1,134 @@
+package narrow
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// validation rule errors, ParamError.Err is one of these
+var (
+	// ErrConflict means the parameter contradicts another condition
+	ErrConflict = errors.New("conflicting condition")
+	// ErrOutOfRange means the value is out of range that API accepts
+	ErrOutOfRange = errors.New("value out of range")
+	// ErrInvalidRange means min is greater than max
+	ErrInvalidRange = errors.New("min is greater than max")
+	// ErrUnknownValue means the value is not defined constant
+	ErrUnknownValue = errors.New("unknown value")
+	// ErrGenreMismatch means the genre does not belong to selected big genres
+	ErrGenreMismatch = errors.New("genre does not belong to big genre")
+	// ErrInvalidNCode means the ncode syntax is wrong
+	ErrInvalidNCode = errors.New("invalid ncode")
+	// ErrUnsupported means the endpoint does not support the parameter
+	ErrUnsupported = errors.New("unsupported parameter")
+	// ErrInvalidDate means no ranking is published on the date for the ranking type
+	ErrInvalidDate = errors.New("invalid date")
+)
+
+// ErrUnknownEndPoint means the URL is not novel api nor R18 api
+var ErrUnknownEndPoint = errors.New("unknown api endpoint")
+
+// ParamError describes a violated rule of one query parameter
+type ParamError struct {
+	// Param is query key such as `length`
+	Param string
+	// Value is offending value
+	Value string
+	// Err is one of ErrConflict, ErrOutOfRange, ...
+	Err error
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("%s=%s: %v", e.Param, e.Value, e.Err)
+}
+
+// Unwrap returns rule error
+func (e *ParamError) Unwrap() error { return e.Err }
+
+// ValidationError contains every violated rule of search parameters
+type ValidationError struct {
+	Errors []*ParamError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, pe := range e.Errors {
+		msgs[i] = pe.Error()
+	}
+	return "invalid params: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns each ParamError
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, pe := range e.Errors {
+		errs[i] = pe
+	}
+	return errs
+}
+
+type paramErrors []*ParamError
+
+func (errs *paramErrors) add(param string, value interface{}, err error) {
+	*errs = append(*errs, &ParamError{Param: param, Value: fmt.Sprintf("%v", value), Err: err})
+}
+
+func (errs paramErrors) result() (bool, error) {
+	if len(errs) == 0 {
+		return true, nil
+	}
+	return false, &ValidationError{Errors: errs}
+}
+
+// api error kinds, APIError.Err is one of these or nil
+var (
+	// ErrEmptyResponse means API returned no result document, not even allcount
+	ErrEmptyResponse = errors.New("empty response")
+	// ErrRateLimited means API refused request by access limit
+	ErrRateLimited = errors.New("rate limited")
+	// ErrMaintenance means API is under maintenance
+	ErrMaintenance = errors.New("under maintenance")
+)
+
+// APIError is returned when API rejects a request or returns unexpected body
+type APIError struct {
+	// StatusCode is HTTP status code of response
+	StatusCode int
+	// Message is error message from API, or head of unexpected body
+	Message string
+	// URL is requested URL
+	URL string
+	// Err is one of ErrEmptyResponse, ErrRateLimited, ErrMaintenance or nil
+	Err error
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("api error status=%d url=%s", e.StatusCode, e.URL)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Unwrap returns error kind
+func (e *APIError) Unwrap() error { return e.Err }
+
+// PageError is returned when a page content of novel could not be fetched
+type PageError struct {
+	NCode      string
+	PageNumber int
+	// URL is requested page URL
+	URL string
+	Err error
+}
+
+func (e *PageError) Error() string {
+	return fmt.Sprintf("fetch %s page %d failed url=%s: %v", e.NCode, e.PageNumber, e.URL, e.Err)
+}
+
+// Unwrap returns cause
+func (e *PageError) Unwrap() error { return e.Err }
@@ -0,0 +1,101 @@
+package narrow
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PageSelection selects episodes of series to download,
+// a page is selected when it matches any of Ranges, Last or Since
+type PageSelection struct {
+	// Ranges is page number ranges such as 120-180, single page is From == To
+	Ranges []PageRange
+	// Last selects latest N episodes
+	Last int
+	// Since selects episodes published or revised at or after Since
+	Since *time.Time
+}
+
+// PageRange is inclusive page number range, To 0 means up to the last page
+type PageRange struct {
+	From int
+	To   int
+}
+
+// PageNumbers returns sorted page numbers selected from index pages
+func (sel *PageSelection) PageNumbers(pages []FetchPage) []int {
+	count := len(pages)
+	selected := make([]bool, count+1)
+	for _, r := range sel.Ranges {
+		to := r.To
+		if to == 0 || to > count {
+			to = count
+		}
+		for n := r.From; n <= to; n++ {
+			if n >= 1 {
+				selected[n] = true
+			}
+		}
+	}
+	if sel.Last > 0 {
+		from := count - sel.Last + 1
+		if from < 1 {
+			from = 1
+		}
+		for n := from; n <= count; n++ {
+			selected[n] = true
+		}
+	}
+	if sel.Since != nil {
+		for i, page := range pages {
+			date := page.PublishDate
+			if page.LastUpdateDate != nil {
+				date = *page.LastUpdateDate
+			}
+			if !date.Before(*sel.Since) {
+				selected[i+1] = true
+			}
+		}
+	}
+
+	var pageNos []int
+	for n := 1; n <= count; n++ {
+		if selected[n] {
+			pageNos = append(pageNos, n)
+		}
+	}
+	return pageNos
+}
+
+// ParsePageRanges parses comma separated pages and ranges such as `1,3,120-180,200-`
+func ParsePageRanges(s string) ([]PageRange, error) {
+	var ranges []PageRange
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		from, to, isRange := strings.Cut(part, "-")
+		r := PageRange{}
+		var err error
+		if r.From, err = strconv.Atoi(from); err != nil || r.From < 1 {
+			return nil, fmt.Errorf("invalid page range %q", part)
+		}
+		switch {
+		case !isRange:
+			r.To = r.From
+		case to == "":
+			r.To = 0
+		default:
+			if r.To, err = strconv.Atoi(to); err != nil || r.To < r.From {
+				return nil, fmt.Errorf("invalid page range %q", part)
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].From < ranges[j].From })
+	return ranges, nil
+}
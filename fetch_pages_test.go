@@ -0,0 +1,89 @@
+package narrow
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParsePageRanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    []PageRange
+		wantErr bool
+	}{
+		{"single", "3", []PageRange{{3, 3}}, false},
+		{"list and ranges", "120-180, 1,200-", []PageRange{{1, 1}, {120, 180}, {200, 0}}, false},
+		{"empty", "", nil, false},
+		{"zero", "0", nil, true},
+		{"reversed", "180-120", nil, true},
+		{"not number", "a-b", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePageRanges(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePageRanges() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePageRanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageSelection_PageNumbers(t *testing.T) {
+	pages := make([]FetchPage, 10)
+	for i := range pages {
+		pages[i].PublishDate = *jstDate(2020, 1, i+1, 0, 0, 0, 0)
+	}
+	pages[2].LastUpdateDate = jstDate(2020, 2, 1, 0, 0, 0, 0)
+
+	tests := []struct {
+		name string
+		sel  PageSelection
+		want []int
+	}{
+		{"none", PageSelection{}, nil},
+		{"ranges", PageSelection{Ranges: []PageRange{{2, 3}, {5, 5}, {9, 0}}}, []int{2, 3, 5, 9, 10}},
+		{"range beyond count", PageSelection{Ranges: []PageRange{{8, 20}, {30, 40}}}, []int{8, 9, 10}},
+		{"last", PageSelection{Last: 3}, []int{8, 9, 10}},
+		{"last greater than count", PageSelection{Last: 30}, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{"since publish or revise", PageSelection{Since: jstDate(2020, 1, 9, 0, 0, 0, 0)}, []int{3, 9, 10}},
+		{"union", PageSelection{Ranges: []PageRange{{1, 1}}, Last: 1, Since: jstDate(2020, 1, 31, 0, 0, 0, 0)}, []int{1, 3, 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sel.PageNumbers(pages); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PageSelection.PageNumbers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Fetch_pages(t *testing.T) {
+	var requests []string
+	c := &Client{httpClient: &http.Client{Transport: fakeContentSite(map[string]string{
+		"/n0000aa/":   readTestData(t, "legacy_index.html"),
+		"/n0000aa/2/": readTestData(t, "legacy_episode.html"),
+		"/n0000aa/3/": readTestData(t, "legacy_episode.html"),
+	}, &requests)}}
+
+	params := &FetchParams{NCode: "n0000aa", WithContent: true, Pages: &PageSelection{Last: 2}}
+	res, err := c.Fetch(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Client.Fetch() error = %v", err)
+	}
+	if want := []string{"/n0000aa/", "/n0000aa/2/", "/n0000aa/3/"}; !reflect.DeepEqual(requests, want) {
+		t.Errorf("Client.Fetch() requests = %v, want %v", requests, want)
+	}
+	wantStatus := []PageStatus{PageStatusSkipped, PageStatusFetched, PageStatusFetched}
+	for i, page := range res.Pages {
+		if page.Status != wantStatus[i] {
+			t.Errorf("Client.Fetch() page %d = %v, want %v", i+1, page.Status, wantStatus[i])
+		}
+	}
+}
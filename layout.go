@@ -0,0 +1,103 @@
+package narrow
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// layout parses one generation of narou reader HTML
+type layout interface {
+	// isSeriesIndex returns true if doc is index page of series, false if short story page
+	isSeriesIndex(doc *goquery.Document) bool
+	parseSeriesIndex(doc *goquery.Document, logger *slog.Logger) (*FetchResult, error)
+	parseShortContent(doc *goquery.Document, logger *slog.Logger) (*FetchResult, error)
+	parseContent(doc *goquery.Document, logger *slog.Logger) (*FetchPage, error)
+	// nextIndexPage returns href of next page of paginated index, or empty
+	nextIndexPage(doc *goquery.Document) string
+}
+
+// detectLayout returns currentLayout for redesigned pages (`p-novel__*`, `p-eplist`), otherwise legacyLayout
+func detectLayout(doc *goquery.Document) layout {
+	if doc.Find(".p-novel__body, .p-eplist, .p-novel__title").Size() > 0 {
+		return currentLayout{}
+	}
+	return legacyLayout{}
+}
+
+// parseIndexEntries reads subtitle, chapter and dates of each episode in index,
+// chapter is the nearest preceding sibling heading of the entry
+func parseIndexEntries(entries *goquery.Selection, subtitleSelector, chapterSelector, dateSelector string, logger *slog.Logger) []FetchPage {
+	pages := make([]FetchPage, entries.Size())
+	entries.Each(func(i int, s *goquery.Selection) {
+		pages[i] = FetchPage{PageNumber: i + 1}
+		subTitle := s.Find(subtitleSelector).First().Text()
+		pages[i].SubTitle = strings.TrimSpace(subTitle)
+		if chapter := s.PrevAllFiltered(chapterSelector).First(); chapter.Size() != 0 {
+			title := strings.TrimSpace(chapter.Text())
+			pages[i].ChapterTitle = &title
+		}
+
+		date := s.Find(dateSelector).First()
+		pubDateStr := date.Text()
+		pubDateStr = kaiRe.ReplaceAllString(pubDateStr, "")
+		pubDateStr = strings.TrimSpace(pubDateStr)
+		pubDate, err := asJST(pubDateStr)
+		if err != nil {
+			logger.Warn("pubdate parse failed", "page", i+1, "value", pubDateStr, "error", err)
+		} else {
+			pages[i].PublishDate = pubDate
+		}
+
+		kai := date.Find("span").First()
+		if kai.Size() == 1 {
+			if upd, ok := kai.Attr("title"); ok {
+				upd = kaikouRe.ReplaceAllString(upd, "")
+				upd = strings.TrimSpace(upd)
+				updateDate, err := asJST(upd)
+				if err != nil {
+					logger.Warn("update date parse failed", "page", i+1, "value", upd, "error", err)
+				} else {
+					pages[i].LastUpdateDate = &updateDate
+				}
+			}
+		}
+	})
+	return pages
+}
+
+// parseShortStory makes single page result of short story
+func parseShortStory(l layout, doc *goquery.Document, title, writerName string, logger *slog.Logger) (*FetchResult, error) {
+	res := &FetchResult{}
+	res.Title = title
+	res.WriterName = writerName
+	res.NovelType = 2
+	res.PageCount = 1
+	res.Pages = make([]FetchPage, 1)
+	page, err := l.parseContent(doc, logger)
+	if err != nil {
+		logger.Warn("parse content page error", "error", err)
+	}
+	page.PageNumber = 1
+	page.Status = PageStatusFetched
+	res.Pages[0] = *page
+	return res, nil
+}
+
+func parseContentLines(doc *goquery.Document, selector string, logger *slog.Logger) []ContentLine {
+	honbun := doc.Find(selector).First()
+	if honbun.Size() == 0 {
+		return nil
+	}
+	lines := honbun.Find("p")
+	content := make([]ContentLine, lines.Size())
+	lines.Each(func(i int, s *goquery.Selection) {
+		raw, err := goquery.OuterHtml(s) // want p#L
+		if err != nil {
+			logger.Warn("raw line error", "selector", selector, "line", i+1, "error", err)
+		}
+		content[i] = parseContentLine(s, raw)
+	})
+	return content
+}
@@ -0,0 +1,79 @@
+package narrow
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// currentLayout parses redesigned reader pages, such as `div.p-novel__body` and `div.p-eplist`
+type currentLayout struct{}
+
+const currentHonbunSelector = ".p-novel__body .p-novel__text:not(.p-novel__text--preface):not(.p-novel__text--afterword)"
+
+// announceCounterRe matches episode counter `4/140` in `div.c-announce`
+var announceCounterRe = regexp.MustCompile(`^[0-9]+\s*/\s*[0-9]+$`)
+
+func (currentLayout) isSeriesIndex(doc *goquery.Document) bool {
+	return doc.Find(".p-eplist").Size() != 0
+}
+
+func (currentLayout) parseSeriesIndex(doc *goquery.Document, logger *slog.Logger) (*FetchResult, error) {
+	res := &FetchResult{}
+	res.Title = currentTitle(doc)
+	res.WriterName = strings.TrimSpace(doc.Find(".p-novel__author").First().Text())
+	res.NovelType = 1
+	res.Abstruct = doc.Find(".p-novel__summary").Text()
+	res.Pages = parseIndexEntries(doc.Find(".p-eplist > .p-eplist__sublist"), "a.p-eplist__subtitle", ".p-eplist__chapter-title", ".p-eplist__update", logger)
+	res.PageCount = len(res.Pages)
+	return res, nil
+}
+
+func (l currentLayout) parseShortContent(doc *goquery.Document, logger *slog.Logger) (*FetchResult, error) {
+	writerName := strings.TrimSpace(doc.Find(".p-novel__author").First().Text())
+	res, err := parseShortStory(l, doc, currentTitle(doc), writerName, logger)
+	if err != nil {
+		return nil, err
+	}
+	// h1.p-novel__title is novel title on short story page, not subtitle
+	res.Pages[0].SubTitle = ""
+	return res, nil
+}
+
+func (currentLayout) parseContent(doc *goquery.Document, logger *slog.Logger) (*FetchPage, error) {
+	page := &FetchPage{}
+	page.SubTitle = strings.TrimSpace(doc.Find("h1.p-novel__title").First().Text())
+	// chapter is shown in announce box with episode counter, such as `<span>序章</span><span>4/140</span>`
+	doc.Find(".c-announce span").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		text := strings.TrimSpace(s.Text())
+		if text == "" || announceCounterRe.MatchString(text) {
+			return true
+		}
+		page.ChapterTitle = &text
+		return false
+	})
+	page.Preface = parseContentLines(doc, ".p-novel__body .p-novel__text--preface", logger)
+	page.Lines = parseContentLines(doc, currentHonbunSelector, logger)
+	page.Afterword = parseContentLines(doc, ".p-novel__body .p-novel__text--afterword", logger)
+	raw, err := doc.Find(".p-novel__body").First().Html()
+	if err != nil {
+		logger.Warn("raw content html error", "error", err)
+	}
+	page.rawHTML = raw
+	return page, nil
+}
+
+func (currentLayout) nextIndexPage(doc *goquery.Document) string {
+	href, _ := doc.Find(".c-pager a.c-pager__item--next").First().Attr("href")
+	return href
+}
+
+func currentTitle(doc *goquery.Document) string {
+	title := strings.TrimSpace(doc.Find("h1.p-novel__title").First().Text())
+	if title == "" {
+		title = strings.TrimSpace(doc.Find("title").First().Text())
+	}
+	return title
+}
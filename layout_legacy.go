@@ -0,0 +1,56 @@
+package narrow
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// legacyLayout parses reader pages before the redesign, such as `#novel_honbun` and `div.index_box`
+type legacyLayout struct{}
+
+func (legacyLayout) isSeriesIndex(doc *goquery.Document) bool {
+	return doc.Find("#novel_ex").Size() != 0
+}
+
+func (legacyLayout) parseSeriesIndex(doc *goquery.Document, logger *slog.Logger) (*FetchResult, error) {
+	res := &FetchResult{}
+	res.Title = strings.TrimSpace(doc.Find("title").First().Text())
+	res.WriterName = strings.TrimSpace(doc.Find("div.novel_writername").First().Text())
+	res.NovelType = 1
+	res.Abstruct = doc.Find("#novel_ex").Text()
+	res.Pages = parseIndexEntries(doc.Find("div.index_box > dl.novel_sublist2"), "dd.subtitle a", "div.chapter_title", "dt.long_update", logger)
+	res.PageCount = len(res.Pages)
+	return res, nil
+}
+
+func (l legacyLayout) parseShortContent(doc *goquery.Document, logger *slog.Logger) (*FetchResult, error) {
+	title := doc.Find("title").First().Text()
+	writerName := strings.TrimSpace(doc.Find("div.novel_writername").First().Text())
+	return parseShortStory(l, doc, title, writerName, logger)
+}
+
+func (legacyLayout) parseContent(doc *goquery.Document, logger *slog.Logger) (*FetchPage, error) {
+	page := &FetchPage{}
+	page.SubTitle = strings.TrimSpace(doc.Find("div.novel_subtitle").First().Text())
+	chapter := doc.Find(".chapter_title")
+	if chapter.Size() != 0 {
+		title := strings.TrimSpace(chapter.First().Text())
+		page.ChapterTitle = &title
+	}
+	page.Preface = parseContentLines(doc, "#novel_p", logger)
+	page.Lines = parseContentLines(doc, "#novel_honbun", logger)
+	page.Afterword = parseContentLines(doc, "#novel_a", logger)
+	raw, err := doc.Find("#novel_color").First().Html()
+	if err != nil {
+		logger.Warn("raw content html error", "error", err)
+	}
+	page.rawHTML = raw
+	return page, nil
+}
+
+func (legacyLayout) nextIndexPage(doc *goquery.Document) string {
+	// legacy index is not paginated
+	return ""
+}
@@ -0,0 +1,88 @@
+package narrow
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+// assertGolden compares JSON of got with testdata/<name>.golden.json
+func assertGolden(t *testing.T, name string, got interface{}) {
+	t.Helper()
+	b, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b = append(b, '\n')
+	path := filepath.Join("testdata", name+".golden.json")
+	if *updateGolden {
+		if err := ioutil.WriteFile(path, b, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden %s: %v", path, err)
+	}
+	if !bytes.Equal(b, want) {
+		t.Errorf("%s mismatch, got\n%s\nwant\n%s", path, b, want)
+	}
+}
+
+func Test_detectLayout(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want layout
+	}{
+		{"legacy index", "legacy_index.html", legacyLayout{}},
+		{"legacy episode", "legacy_episode.html", legacyLayout{}},
+		{"legacy short", "legacy_short.html", legacyLayout{}},
+		{"current index", "current_index.html", currentLayout{}},
+		{"current episode", "current_episode.html", currentLayout{}},
+		{"current short", "current_short.html", currentLayout{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(readTestData(t, tt.file)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := detectLayout(doc); got != tt.want {
+				t.Errorf("detectLayout() = %T, want %T", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseFetchedContent_golden(t *testing.T) {
+	discard := slog.New(slog.NewTextHandler(ioutil.Discard, nil))
+	for _, name := range []string{"legacy_index", "legacy_short", "current_index", "current_short"} {
+		t.Run(name, func(t *testing.T) {
+			res, _, err := parseFetchedContent(strings.NewReader(readTestData(t, name+".html")), discard)
+			if err != nil {
+				t.Fatalf("parseFetchedContent() error = %v", err)
+			}
+			assertGolden(t, name, res)
+		})
+	}
+	for _, name := range []string{"legacy_episode", "current_episode"} {
+		t.Run(name, func(t *testing.T) {
+			page, err := parseFetchedPage(strings.NewReader(readTestData(t, name+".html")), discard)
+			if err != nil {
+				t.Fatalf("parseFetchedPage() error = %v", err)
+			}
+			assertGolden(t, name, page)
+		})
+	}
+}
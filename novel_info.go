@@ -0,0 +1,41 @@
+package narrow
+
+import "strings"
+
+// nocGenreSites maps nocgenre to R18 site
+var nocGenreSites = map[NocGenre]FetchSite{
+	NocGenreNocturne:       FetchSiteNocturne,
+	NocGenreMoonlightWomen: FetchSiteMoonLight,
+	NocGenreMoonlightBL:    FetchSiteMoonLight,
+	NocGenreMidnight:       FetchSiteMidNight,
+}
+
+// IsR18 returns info is result of R18 api
+func (info *NovelInfo) IsR18() bool { return info.fromSearchX }
+
+// Site returns site the novel is published on, R18 novel without nocgenre is FetchSiteNocturne
+func (info *NovelInfo) Site() FetchSite {
+	if !info.fromSearchX {
+		return FetchSiteNarou
+	}
+	if info.NocturneGenre != nil {
+		if site, ok := nocGenreSites[NocGenre(*info.NocturneGenre)]; ok {
+			return site
+		}
+	}
+	return FetchSiteNocturne
+}
+
+// ReaderURL returns index page URL such as `https://novel18.syosetu.com/n1234ab/` and site of the novel,
+// URL is empty if info has no ncode
+func (info *NovelInfo) ReaderURL() (string, FetchSite) {
+	site := info.Site()
+	if info.NCode == nil || *info.NCode == "" {
+		return "", site
+	}
+	base := NarouContentBaseURL
+	if site != FetchSiteNarou {
+		base = NarouR18ContentBaseURL
+	}
+	return base + strings.ToLower(*info.NCode) + "/", site
+}
@@ -0,0 +1,44 @@
+package narrow
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestNovelInfo_ReaderURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     NovelInfo
+		wantURL  string
+		wantSite FetchSite
+	}{
+		{"narou", NovelInfo{NCode: strp("N1234AB")}, "https://ncode.syosetu.com/n1234ab/", FetchSiteNarou},
+		{"nocturne", NovelInfo{NCode: strp("N1234AB"), NocturneGenre: intp(1), fromSearchX: true}, "https://novel18.syosetu.com/n1234ab/", FetchSiteNocturne},
+		{"moonlight bl", NovelInfo{NCode: strp("N1234AB"), NocturneGenre: intp(3), fromSearchX: true}, "https://novel18.syosetu.com/n1234ab/", FetchSiteMoonLight},
+		{"midnight", NovelInfo{NCode: strp("N1234AB"), NocturneGenre: intp(4), fromSearchX: true}, "https://novel18.syosetu.com/n1234ab/", FetchSiteMidNight},
+		{"r18 without nocgenre", NovelInfo{NCode: strp("N1234AB"), fromSearchX: true}, "https://novel18.syosetu.com/n1234ab/", FetchSiteNocturne},
+		{"no ncode", NovelInfo{}, "", FetchSiteNarou},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotURL, gotSite := tt.info.ReaderURL()
+			if gotURL != tt.wantURL || gotSite != tt.wantSite {
+				t.Errorf("NovelInfo.ReaderURL() = %v, %v, want %v, %v", gotURL, gotSite, tt.wantURL, tt.wantSite)
+			}
+		})
+	}
+}
+
+func TestClient_Search_r18(t *testing.T) {
+	c := &Client{httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return textResponse(http.StatusOK, `[{"allcount":1},{"ncode":"N1234AB"}]`), nil
+	})}}
+	res, err := c.Search(context.Background(), NewSearchR18Params())
+	if err != nil {
+		t.Fatalf("Client.Search() error = %v", err)
+	}
+	if u, site := res.NovelInfos[0].ReaderURL(); !res.NovelInfos[0].IsR18() || u != "https://novel18.syosetu.com/n1234ab/" || site != FetchSiteNocturne {
+		t.Errorf("Client.Search() NovelInfos[0].ReaderURL() = %v, %v", u, site)
+	}
+}
@@ -0,0 +1,179 @@
+package narrow
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RateLimit is token bucket setting, zero value means no limit
+type RateLimit struct {
+	// Interval is time to refill one token
+	Interval time.Duration
+	// Burst is bucket size, treated as 1 if less than 1
+	Burst int
+	// Jitter is max random delay added before each request
+	Jitter time.Duration
+}
+
+// default politeness
+var (
+	// DefaultAPIRateLimit is used for api.syosetu.com
+	DefaultAPIRateLimit = RateLimit{Interval: 200 * time.Millisecond, Burst: 5}
+	// DefaultContentRateLimit is used for ncode.syosetu.com and novel18.syosetu.com
+	DefaultContentRateLimit = RateLimit{Interval: time.Second, Burst: 1, Jitter: 500 * time.Millisecond}
+)
+
+// WithAPIRateLimit set rate limit for each api host, RateLimit{} disables it
+func WithAPIRateLimit(l RateLimit) ClientOption {
+	return func(c *Client) { c.limiter.setLimit(endpointAPI, l) }
+}
+
+// WithContentRateLimit set rate limit for each reader page host, RateLimit{} disables it
+func WithContentRateLimit(l RateLimit) ClientOption {
+	return func(c *Client) {
+		c.limiter.setLimit(endpointIndex, l)
+		c.limiter.setLimit(endpointEpisode, l)
+	}
+}
+
+// endpoint is kind of request
+type endpoint int
+
+const (
+	// endpointAPI is search api
+	endpointAPI endpoint = iota
+	// endpointIndex is novel top page, series index or short story
+	endpointIndex
+	// endpointEpisode is episode page of series
+	endpointEpisode
+)
+
+// rateLimiter holds token bucket for each endpoint and host, shared by all requests of Client
+type rateLimiter struct {
+	mu      sync.Mutex
+	limits  map[endpoint]RateLimit
+	buckets map[bucketKey]*bucket
+}
+
+// bucketKey separates buckets of api and reader pages even if they share a host, such as a mirror
+type bucketKey struct {
+	ep   endpoint
+	host string
+}
+
+// bucketEndpoint returns endpoint owning bucket, index and episode pages share one bucket of reader pages
+func bucketEndpoint(ep endpoint) endpoint {
+	if ep == endpointEpisode {
+		return endpointIndex
+	}
+	return ep
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		limits: map[endpoint]RateLimit{
+			endpointAPI:     DefaultAPIRateLimit,
+			endpointIndex:   DefaultContentRateLimit,
+			endpointEpisode: DefaultContentRateLimit,
+		},
+		buckets: make(map[bucketKey]*bucket),
+	}
+}
+
+func (rl *rateLimiter) setLimit(ep endpoint, l RateLimit) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.limits[ep] = l
+	// limits changed, start over
+	rl.buckets = make(map[bucketKey]*bucket)
+}
+
+// wait blocks until request to host is allowed or ctx is done
+func (rl *rateLimiter) wait(ctx context.Context, ep endpoint, host string) error {
+	if rl == nil {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		// do not take token for request never sent
+		return err
+	}
+	rl.mu.Lock()
+	l := rl.limits[ep]
+	if l.Interval <= 0 && l.Jitter <= 0 {
+		rl.mu.Unlock()
+		return nil
+	}
+	key := bucketKey{ep: bucketEndpoint(ep), host: host}
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &bucket{limit: l}
+		rl.buckets[key] = b
+	}
+	rl.mu.Unlock()
+
+	d := b.reserve(time.Now())
+	if l.Jitter > 0 {
+		d += time.Duration(rand.Int63n(int64(l.Jitter)))
+	}
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		b.release()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// bucket is token bucket, tokens may go negative to queue concurrent callers
+type bucket struct {
+	mu     sync.Mutex
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes one token and returns how long caller should wait
+func (b *bucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.limit.Interval <= 0 {
+		return 0
+	}
+	burst := float64(b.limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	if b.last.IsZero() {
+		b.tokens = burst
+	} else {
+		b.tokens += float64(now.Sub(b.last)) / float64(b.limit.Interval)
+		if b.tokens > burst {
+			b.tokens = burst
+		}
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens * float64(b.limit.Interval))
+}
+
+// release gives back token of reservation which was not used
+func (b *bucket) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.limit.Interval > 0 {
+		b.tokens++
+	}
+}
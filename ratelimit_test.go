@@ -0,0 +1,115 @@
+package narrow
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_bucket_reserve(t *testing.T) {
+	base := time.Unix(1500000000, 0)
+	tests := []struct {
+		name  string
+		limit RateLimit
+		at    []time.Duration
+		want  []time.Duration
+	}{
+		{"no interval", RateLimit{}, []time.Duration{0, 0, 0}, []time.Duration{0, 0, 0}},
+		{"burst 1",
+			RateLimit{Interval: time.Second, Burst: 1},
+			[]time.Duration{0, 0, 0, 3 * time.Second},
+			[]time.Duration{0, time.Second, 2 * time.Second, 0}},
+		{"burst 3",
+			RateLimit{Interval: time.Second, Burst: 3},
+			[]time.Duration{0, 0, 0, 0, 500 * time.Millisecond},
+			[]time.Duration{0, 0, 0, time.Second, 1500 * time.Millisecond}},
+		{"refill is capped by burst",
+			RateLimit{Interval: time.Second, Burst: 2},
+			[]time.Duration{0, 10 * time.Second, 10 * time.Second, 10 * time.Second},
+			[]time.Duration{0, 0, 0, time.Second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &bucket{limit: tt.limit}
+			for i, at := range tt.at {
+				if got := b.reserve(base.Add(at)); got != tt.want[i] {
+					t.Errorf("bucket.reserve() #%d = %v, want %v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_rateLimiter_wait(t *testing.T) {
+	rl := newRateLimiter()
+	rl.setLimit(endpointAPI, RateLimit{Interval: 20 * time.Millisecond, Burst: 2})
+	rl.setLimit(endpointEpisode, RateLimit{})
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := rl.wait(context.Background(), endpointAPI, "api.syosetu.com"); err != nil {
+				t.Errorf("rateLimiter.wait() error = %v", err)
+			}
+			// other host has own bucket, unlimited endpoint never waits
+			if err := rl.wait(context.Background(), endpointEpisode, "ncode.syosetu.com"); err != nil {
+				t.Errorf("rateLimiter.wait() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	// 2 by burst, 4 more by 20ms each
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("rateLimiter.wait() 6 requests in %v, want >= 80ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rl.setLimit(endpointIndex, RateLimit{Interval: time.Hour, Burst: 1})
+	rl.wait(context.Background(), endpointIndex, "ncode.syosetu.com")
+	if err := rl.wait(ctx, endpointIndex, "ncode.syosetu.com"); err != context.Canceled {
+		t.Errorf("rateLimiter.wait() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func Test_rateLimiter_wait_endpointBucket(t *testing.T) {
+	rl := newRateLimiter()
+	rl.setLimit(endpointAPI, RateLimit{Interval: time.Hour, Burst: 1})
+	rl.setLimit(endpointIndex, RateLimit{Interval: time.Millisecond, Burst: 1})
+	rl.setLimit(endpointEpisode, RateLimit{Interval: time.Millisecond, Burst: 1})
+
+	// api and content on same host, such as a mirror, do not share the bucket
+	ctx := context.Background()
+	if err := rl.wait(ctx, endpointIndex, "127.0.0.1"); err != nil {
+		t.Fatalf("rateLimiter.wait() error = %v", err)
+	}
+	if err := rl.wait(ctx, endpointAPI, "127.0.0.1"); err != nil {
+		t.Fatalf("rateLimiter.wait() error = %v", err)
+	}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := rl.wait(ctx, endpointEpisode, "127.0.0.1"); err != nil {
+			t.Fatalf("rateLimiter.wait() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("rateLimiter.wait() content waited %v by api limit", elapsed)
+	}
+
+	// cancelled context does not take token
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	rl.setLimit(endpointAPI, RateLimit{Interval: time.Hour, Burst: 1})
+	if err := rl.wait(cancelled, endpointAPI, "127.0.0.1"); err != context.Canceled {
+		t.Errorf("rateLimiter.wait() error = %v, want %v", err, context.Canceled)
+	}
+	timeout, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if err := rl.wait(timeout, endpointAPI, "127.0.0.1"); err != nil {
+		t.Errorf("rateLimiter.wait() error = %v, want token left", err)
+	}
+}
@@ -0,0 +1,157 @@
+package narrow
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures retry of failed GET requests, zero value disables retry
+type RetryPolicy struct {
+	// MaxAttempts is max number of requests including the first one
+	MaxAttempts int
+	// BaseDelay is delay before first retry, doubled for each retry
+	BaseDelay time.Duration
+	// MaxDelay caps backoff delay, but not `Retry-After`
+	MaxDelay time.Duration
+	// OnRetry is called before each retry if not nil
+	OnRetry func(RetryEvent)
+}
+
+// RetryEvent describes a failed attempt which will be retried
+type RetryEvent struct {
+	// Attempt is number of failed attempt, starts from 1
+	Attempt int
+	// URL is requested URL
+	URL string
+	// StatusCode is HTTP status code, 0 if request failed without response
+	StatusCode int
+	// Err is request error, nil if response has retryable status
+	Err error
+	// Delay is wait time before next attempt
+	Delay time.Duration
+}
+
+// DefaultRetryPolicy is used by NewClient
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: 30 * time.Second}
+
+// WithRetryPolicy set retry policy, RetryPolicy{} disables retry
+func WithRetryPolicy(p RetryPolicy) ClientOption {
+	return func(c *Client) { c.retry = p }
+}
+
+// retryableStatus returns whether the status is transient
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryableError returns whether the request error is transient, such as connection reset
+func retryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// backoff returns delay before retry after attempt, `Retry-After` of res has priority
+func (p RetryPolicy) backoff(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if d, ok := parseRetryAfter(res.Header.Get("Retry-After"), time.Now()); ok {
+			return d
+		}
+	}
+	d := p.BaseDelay << uint(attempt-1)
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	// equal jitter, [d/2, d)
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)))
+}
+
+// parseRetryAfter parses delay-seconds or HTTP-date
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	d := t.Sub(now)
+	if d < 0 {
+		d = 0
+	}
+	return d, true
+}
+
+// doWithRetry calls do until it succeeds, fails permanently or attempts run out
+func (c *Client) doWithRetry(ctx context.Context, u string, do func() (*http.Response, error)) (*http.Response, error) {
+	p := c.retry
+	for attempt := 1; ; attempt++ {
+		res, err := do()
+		if attempt >= p.MaxAttempts {
+			return res, err
+		}
+
+		ev := RetryEvent{Attempt: attempt, URL: u, Err: err}
+		switch {
+		case err != nil:
+			if !retryableError(err) {
+				return res, err
+			}
+		case retryableStatus(res.StatusCode):
+			ev.StatusCode = res.StatusCode
+		default:
+			return res, nil
+		}
+
+		ev.Delay = p.backoff(attempt, res)
+		if res != nil {
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		}
+		if p.OnRetry != nil {
+			p.OnRetry(ev)
+		}
+
+		timer := time.NewTimer(ev.Delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
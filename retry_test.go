@@ -0,0 +1,122 @@
+package narrow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func Test_parseRetryAfter(t *testing.T) {
+	now := time.Date(2019, 8, 16, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		v      string
+		want   time.Duration
+		wantOk bool
+	}{
+		{"empty", "", 0, false},
+		{"seconds", "120", 2 * time.Minute, true},
+		{"negative seconds", "-1", 0, false},
+		{"http date", "Fri, 16 Aug 2019 08:00:30 GMT", 30 * time.Second, true},
+		{"past date", "Fri, 16 Aug 2019 07:00:00 GMT", 0, true},
+		{"garbage", "soon", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.v, now)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", tt.v, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 3 * time.Second}
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{1, 500 * time.Millisecond, time.Second},
+		{2, time.Second, 2 * time.Second},
+		{3, 1500 * time.Millisecond, 3 * time.Second},
+		{10, 1500 * time.Millisecond, 3 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("attempt %d", tt.attempt), func(t *testing.T) {
+			if got := p.backoff(tt.attempt, nil); got < tt.min || got >= tt.max {
+				t.Errorf("RetryPolicy.backoff(%d) = %v, want [%v, %v)", tt.attempt, got, tt.min, tt.max)
+			}
+		})
+	}
+
+	res := textResponse(http.StatusServiceUnavailable, "")
+	res.Header.Set("Retry-After", "60")
+	if got := p.backoff(1, res); got != time.Minute {
+		t.Errorf("RetryPolicy.backoff() with Retry-After = %v, want %v", got, time.Minute)
+	}
+}
+
+func TestClient_get_retry(t *testing.T) {
+	type reply struct {
+		status int
+		err    error
+	}
+	tests := []struct {
+		name        string
+		replies     []reply
+		maxAttempts int
+		wantStatus  int
+		wantErr     bool
+		wantRetries int
+	}{
+		{"success", []reply{{200, nil}}, 3, 200, false, 0},
+		{"503 then success", []reply{{503, nil}, {503, nil}, {200, nil}}, 3, 200, false, 2},
+		{"connection reset then success", []reply{{0, syscall.ECONNRESET}, {200, nil}}, 3, 200, false, 1},
+		{"attempts run out", []reply{{503, nil}, {503, nil}, {503, nil}}, 3, 503, false, 2},
+		{"not retryable status", []reply{{404, nil}, {200, nil}}, 3, 404, false, 0},
+		{"not retryable error", []reply{{0, errors.New("x509: certificate signed by unknown authority")}, {200, nil}}, 3, 0, true, 0},
+		{"retry disabled", []reply{{503, nil}, {200, nil}}, 0, 503, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := 0
+			transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				r := tt.replies[n]
+				n++
+				if r.err != nil {
+					return nil, r.err
+				}
+				res := textResponse(r.status, "")
+				res.Header.Set("Retry-After", "0")
+				return res, nil
+			})
+			var events []RetryEvent
+			c := &Client{
+				httpClient: &http.Client{Transport: transport},
+				retry: RetryPolicy{MaxAttempts: tt.maxAttempts, BaseDelay: time.Millisecond,
+					OnRetry: func(ev RetryEvent) { events = append(events, ev) }},
+			}
+			res, err := c.get(context.Background(), endpointAPI, parseURL(NarouAPIEndPoint))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.get() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if res != nil && res.StatusCode != tt.wantStatus {
+				t.Errorf("Client.get() status = %v, want %v", res.StatusCode, tt.wantStatus)
+			}
+			if len(events) != tt.wantRetries {
+				t.Errorf("Client.get() retried %d times, want %d", len(events), tt.wantRetries)
+			}
+			for i, ev := range events {
+				if ev.Attempt != i+1 || ev.URL != NarouAPIEndPoint {
+					t.Errorf("RetryEvent = %+v", ev)
+				}
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package narrow
 import (
 	"fmt"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 )
@@ -19,29 +20,58 @@ func NewSearchParams() *SearchParams {
 	return params
 }
 
+// copy returns deep copy of params
+func (params *SearchParams) copy() *SearchParams {
+	cp := *params
+	cp.outputFields = append([]OutputField(nil), params.outputFields...)
+	cp.words = append([]string(nil), params.words...)
+	cp.notWords = append([]string(nil), params.notWords...)
+	cp.searchFields = append([]SearchField(nil), params.searchFields...)
+	cp.bigGenres = append([]BigGenre(nil), params.bigGenres...)
+	cp.notBigGenres = append([]BigGenre(nil), params.notBigGenres...)
+	cp.genres = append([]Genre(nil), params.genres...)
+	cp.notGenres = append([]Genre(nil), params.notGenres...)
+	cp.userIDs = append([]int(nil), params.userIDs...)
+	cp.requiredKeywordFlags = make(map[requiredKeyword]bool)
+	for k, v := range params.requiredKeywordFlags {
+		cp.requiredKeywordFlags[k] = v
+	}
+	cp.lengths = params.lengths.copy()
+	cp.kaiwaritus = params.kaiwaritus.copy()
+	cp.sasies = params.sasies.copy()
+	cp.readTimes = params.readTimes.copy()
+	cp.ncodes = append([]string(nil), params.ncodes...)
+	cp.buntais = append([]Buntai(nil), params.buntais...)
+	cp.opts = make(map[optionName]bool)
+	for k, v := range params.opts {
+		cp.opts[k] = v
+	}
+	return &cp
+}
+
 // ToURL return full URL or nil if params contains invalid condition
 func (params *SearchParams) ToURL() (*url.URL, error) {
-	return params.makeFullURL(params.endPointURL(), params.toQueryFuncs())
+	return params.makeFullURL(params.endPointURL(), params.Valid, params.toQueryFuncs())
 }
 
 type toQueryFunc func() url.Values
 
-func (params *SearchParams) makeFullURL(endPoint string, funcs []toQueryFunc) (*url.URL, error) {
+func (params *SearchParams) makeFullURL(endPoint string, valid func() (bool, error), funcs []toQueryFunc) (*url.URL, error) {
 	fullURL, err := url.Parse(endPoint)
 	if err != nil {
 		return nil, err
 	}
 
+	if ok, err := valid(); !ok {
+		return nil, err
+	}
+
 	q := fullURL.Query()
 	defer func() { fullURL.RawQuery = q.Encode() }()
 
 	// set output format
 	q.Add(outputFormatKey, outputFormat)
 
-	if ok, err := params.Valid(); !ok {
-		return nil, err
-	}
-
 	for _, f := range funcs {
 		if m := f(); len(m) != 0 {
 			for k, vs := range m {
@@ -82,16 +112,190 @@ func (params *SearchParams) toQueryFuncs() []toQueryFunc {
 		params.queryFromPickup,
 		params.queryFromLastUp,
 		params.queryFromOpt,
+		params.queryFromOrder,
+		params.queryFromGzip,
 	}
 }
 
-// Valid returns params is OK or not
+// Valid returns params is OK or not, error is *ValidationError listing every violated rule
 func (params *SearchParams) Valid() (bool, error) {
 	if params == nil {
 		return true, nil
 	}
-	// TODO: impl.
-	return true, nil
+	errs := params.validateCommon()
+	params.validateGenres(&errs)
+	// xid is output of R18 api only
+	for _, f := range params.outputFields {
+		if f == OutputFieldXID {
+			errs.add(keyOutputField, int(f), ErrUnsupported)
+		}
+	}
+	return errs.result()
+}
+
+// validateCommon checks conditions shared by novel api and R18 api
+func (params *SearchParams) validateCommon() paramErrors {
+	var errs paramErrors
+
+	if params.limit != 0 && (params.limit < minLimit || params.limit > maxLimit) {
+		errs.add("lim", params.limit, ErrOutOfRange)
+	}
+	if params.offset != 0 && (params.offset < minOffset || params.offset > maxOffset) {
+		errs.add("st", params.offset, ErrOutOfRange)
+	}
+	if params.gzip != 0 && (params.gzip < minGzip || params.gzip > maxGzip) {
+		errs.add(keyGzip, params.gzip, ErrOutOfRange)
+	}
+	if _, ok := orderItemNames[params.order]; !ok {
+		errs.add("order", int(params.order), ErrUnknownValue)
+	}
+	for _, f := range params.outputFields {
+		if _, ok := outputFieldShortNames[f]; !ok {
+			errs.add(keyOutputField, int(f), ErrUnknownValue)
+		}
+	}
+	for _, f := range params.searchFields {
+		if _, ok := searchFieldNames[f]; !ok && f != SearchFieldAll {
+			errs.add("searchfield", int(f), ErrUnknownValue)
+		}
+	}
+	for _, id := range params.userIDs {
+		if id < 1 {
+			errs.add(keyUserID, id, ErrOutOfRange)
+		}
+	}
+
+	params.validateRequiredKeywords(&errs)
+
+	params.lengths.validate(&errs, keyLength, 0, -1)
+	params.kaiwaritus.validate(&errs, "kaiwaritu", 0, 100)
+	params.sasies.validate(&errs, keySasie, 0, -1)
+	params.readTimes.validate(&errs, keyReadTime, 0, -1)
+
+	for _, ncode := range params.ncodes {
+		if !ncodeRe.MatchString(ncode) {
+			errs.add(keyNCode, ncode, ErrInvalidNCode)
+		}
+	}
+	if _, ok := novelStateShortNames[params.state]; !ok {
+		errs.add(keyNovelState, int(params.state), ErrUnknownValue)
+	}
+	for _, b := range params.buntais {
+		if _, ok := buntaiNames[b]; !ok {
+			errs.add(keyBuntai, int(b), ErrUnknownValue)
+		}
+	}
+	if params.stopState < StopStateAll || params.stopState > StopStateOnly {
+		errs.add(keyStop, int(params.stopState), ErrUnknownValue)
+	}
+	if params.pickupState < PickupStateNone || params.pickupState > PickupStatePickup {
+		errs.add(keyIsPickup, int(params.pickupState), ErrUnknownValue)
+	}
+
+	switch params.lastUp {
+	case LastUpTypeNone:
+	case LastUpTypeTimeStamp:
+		if params.lastUpStart.After(params.lastUpEnd) {
+			errs.add(keyLastUp, fmt.Sprintf("%d-%d", params.lastUpStart.Unix(), params.lastUpEnd.Unix()), ErrInvalidRange)
+		}
+	default:
+		if _, ok := lastUpTypeNames[params.lastUp]; !ok {
+			errs.add(keyLastUp, int(params.lastUp), ErrUnknownValue)
+		}
+	}
+
+	return errs
+}
+
+func (params *SearchParams) validateRequiredKeywords(errs *paramErrors) {
+	pairs := []struct {
+		is, not       requiredKeyword
+		isKey, notKey string
+	}{
+		{requiredKeywordIsR15, requiredKeywordIsNotR15, "isr15", "notr15"},
+		{requiredKeywordIsBL, requiredKeywordIsNotBL, "isbl", "notbl"},
+		{requiredKeywordIsGL, requiredKeywordIsNotGL, "isgl", "notgl"},
+		{requiredKeywordIsZankoku, requiredKeywordIsNotZankoku, "iszankoku", "notzankoku"},
+		{requiredKeywordIsTensei, requiredKeywordIsNotTensei, "istensei", "nottensei"},
+		{requiredKeywordIsTenni, requiredKeywordIsNotTenni, "istenni", "nottenni"},
+	}
+	for _, p := range pairs {
+		if params.requiredKeywordFlags[p.is] && params.requiredKeywordFlags[p.not] {
+			errs.add(p.isKey, p.notKey, ErrConflict)
+		}
+	}
+	// istt means tensei or tenni, never matches with both of nottensei and nottenni
+	if params.IsTT() && params.IsNotTensei() && params.IsNotTenni() {
+		errs.add("istt", "nottensei-nottenni", ErrConflict)
+	}
+}
+
+// validateGenres checks `biggenre`, `genre` and their `not` conditions
+func (params *SearchParams) validateGenres(errs *paramErrors) {
+	bigs := make(map[BigGenre]bool)
+	for _, g := range params.bigGenres {
+		if _, ok := bigGenreNames[g]; !ok {
+			errs.add(keyBigGenre, int(g), ErrUnknownValue)
+		}
+		bigs[g] = true
+	}
+	notBigs := make(map[BigGenre]bool)
+	for _, g := range params.notBigGenres {
+		if _, ok := bigGenreNames[g]; !ok {
+			errs.add(keyNotBigGenre, int(g), ErrUnknownValue)
+		}
+		if bigs[g] {
+			errs.add(keyNotBigGenre, int(g), ErrConflict)
+		}
+		notBigs[g] = true
+	}
+
+	genres := make(map[Genre]bool)
+	for _, g := range params.genres {
+		genres[g] = true
+		if _, ok := genreNames[g]; !ok {
+			errs.add(keyGenre, int(g), ErrUnknownValue)
+			continue
+		}
+		big := g.bigGenre()
+		if len(bigs) != 0 && !bigs[big] {
+			errs.add(keyGenre, int(g), ErrGenreMismatch)
+		}
+		if notBigs[big] {
+			errs.add(keyGenre, int(g), ErrConflict)
+		}
+	}
+	for _, g := range params.notGenres {
+		if _, ok := genreNames[g]; !ok {
+			errs.add(keyNotGenre, int(g), ErrUnknownValue)
+		}
+		if genres[g] {
+			errs.add(keyNotGenre, int(g), ErrConflict)
+		}
+	}
+}
+
+// validate checks each value in [lower, upper] (upper < 0 means no limit),
+// single value is exclusive with min/max, and min <= max
+func (mmp minmaxPair) validate(errs *paramErrors, key string, lower, upper int) {
+	for _, k := range []mmpKey{mmpSingle, mmpMin, mmpMax} {
+		v, ok := mmp[k]
+		if !ok {
+			continue
+		}
+		if v < lower || (upper >= 0 && v > upper) {
+			errs.add(key, v, ErrOutOfRange)
+		}
+	}
+	single, hasSingle := mmp[mmpSingle]
+	min, hasMin := mmp[mmpMin]
+	max, hasMax := mmp[mmpMax]
+	if hasSingle && (hasMin || hasMax) {
+		errs.add(key, single, ErrConflict)
+	}
+	if hasMin && hasMax && min > max {
+		errs.add(key, fmt.Sprintf("%d-%d", min, max), ErrInvalidRange)
+	}
 }
 
 const minLimit, maxLimit = 1, 500
@@ -142,6 +346,30 @@ func (params *SearchParams) queryFromStart() url.Values {
 	return vs
 }
 
+const minGzip, maxGzip = 1, 5
+
+// Gzip return `gzip` compression level parameter
+func (params *SearchParams) Gzip() int { return params.gzip }
+
+// SetGzip set `gzip` compression level parameter, response is decompressed transparently
+func (params *SearchParams) SetGzip(level int) {
+	if level < minGzip || level > maxGzip {
+		return
+	}
+	params.gzip = level
+}
+
+// ClearGzip clear `gzip` parameter
+func (params *SearchParams) ClearGzip() { params.gzip = 0 }
+
+func (params *SearchParams) queryFromGzip() url.Values {
+	vs := make(url.Values)
+	if params.gzip != 0 {
+		vs.Set(keyGzip, fmt.Sprintf("%d", params.gzip))
+	}
+	return vs
+}
+
 // Order return `order`
 func (params *SearchParams) Order() OrderItem { return params.order }
 
@@ -567,7 +795,7 @@ func (params *SearchParams) queryFromNotGenre() url.Values {
 // UserIDs return `userid` param
 func (params *SearchParams) UserIDs() []int { return params.userIDs }
 
-// AddUserIDs add search user ids
+// AddUserIDs add search user ids, SearchR18Params does not support it, use AddXIDs instead
 func (params *SearchParams) AddUserIDs(users []int) {
 	ids := make(map[int]int)
 	i := 0
@@ -798,7 +1026,7 @@ func (params *SearchParams) queryFromRequiredKeywords() url.Values {
 		vs.Set("notzankoku", "1")
 	}
 	if params.IsTensei() {
-		vs.Set("istesei", "1")
+		vs.Set("istensei", "1")
 	}
 	if params.IsNotTensei() {
 		vs.Set("nottensei", "1")
@@ -1021,17 +1249,20 @@ func (params *SearchParams) NCodes() []string { return params.ncodes }
 // AddNCodes add search ncodes
 func (params *SearchParams) AddNCodes(ncodes []string) {
 	ws := make(map[string]int)
-	for _, w := range ncodes {
-		ws[w] = 1
-	}
+	i := 0
 	for _, w := range params.ncodes {
-		ws[w] = 1
+		ws[w] = i
+		i++
+	}
+	for _, w := range ncodes {
+		if _, has := ws[w]; !has {
+			ws[w] = i
+			i++
+		}
 	}
-	i := 0
 	params.ncodes = make([]string, len(ws))
-	for w := range ws {
-		params.ncodes[i] = w
-		i++
+	for w, idx := range ws {
+		params.ncodes[idx] = w
 	}
 }
 
@@ -1264,9 +1495,11 @@ const (
 	keyStop        = "stop"
 	keyIsPickup    = "ispickup"
 	keyLastUp      = "lastup"
+	keyGzip        = "gzip"
 
 	keyNocGenre    = "nocgenre"
 	keyNotNocGenre = "notnocgenre"
+	keyXID         = "xid"
 )
 
 var outputFieldShortNames = map[OutputField]string{
@@ -1313,6 +1546,8 @@ var outputFieldShortNames = map[OutputField]string{
 	OutputFieldYearlyPoint:  "yp",
 
 	OutputFieldImpressionCount: "imp",
+
+	OutputFieldXID: "xid",
 }
 
 var orderItemNames = map[OrderItem]string{
@@ -1338,6 +1573,57 @@ var searchFieldNames = map[SearchField]string{
 	SearchFieldWriter:  "wname",
 }
 
+var bigGenreNames = map[BigGenre]string{
+	BigGenreRenai:    "恋愛",
+	BigGenreFantasy:  "ファンタジー",
+	BigGenreBungei:   "文芸",
+	BigGenreSF:       "SF",
+	BigGenreOther:    "その他",
+	BigGenreNonGenre: "ノンジャンル",
+}
+
+var genreNames = map[Genre]string{
+	GenreRenaiIsekai:   "異世界〔恋愛〕",
+	GenreRenaiGenjitsu: "現実世界〔恋愛〕",
+
+	GenreFantasyHighFantasy: "ハイファンタジー〔ファンタジー〕",
+	GenreFantasyLowFantasy:  "ローファンタジー〔ファンタジー〕",
+
+	GenreBungeiJunbungaku: "純文学〔文芸〕",
+	GenreBungeiHumanDrama: "ヒューマンドラマ〔文芸〕",
+	GenreBungeiHistory:    "歴史〔文芸〕",
+	GenreBungeiMistrey:    "推理〔文芸〕",
+	GenreBungeiHorror:     "ホラー〔文芸〕",
+	GenreBungeiAction:     "アクション〔文芸〕",
+	GenreBungeiComedy:     "コメディー〔文芸〕",
+
+	GenreSFVRGame: "VRゲーム〔SF〕",
+	GenreSFSpace:  "宇宙〔SF〕",
+	GenreSFSF:     "空想科学〔SF〕",
+	GenreSFPanic:  "パニック〔SF〕",
+
+	GenreOtherFairyTale: "童話〔その他〕",
+	GenreOtherPoetry:    "詩〔その他〕",
+	GenreOtherEssei:     "エッセイ〔その他〕",
+	GenreOtherReplay:    "リプレイ〔その他〕",
+	GenreOtherOther:     "その他〔その他〕",
+
+	GenreNongenreNongenre: "ノンジャンル〔ノンジャンル〕",
+}
+
+// bigGenre returns BigGenre which genre belongs to, 101 -> 1, 9801 -> 98
+func (g Genre) bigGenre() BigGenre { return BigGenre(int(g) / 100) }
+
+var buntaiNames = map[Buntai]string{
+	BuntaiNoIndentManyEmptyLines:    "1",
+	BuntaiNoIndentAveraegEmpytLines: "2",
+	BuntaiIndentManyEmptyLines:      "4",
+	BuntaiIndentAverageEmptyLines:   "6",
+}
+
+// ncodeRe matches `N0000A`, `n1234ab`
+var ncodeRe = regexp.MustCompile(`^[nN][0-9]{4}[a-zA-Z]{1,2}$`)
+
 var novelStateShortNames = map[NovelState]string{
 	NovelStateAll:                 "",
 	NovelStateShortStory:          "t",
@@ -1397,3 +1683,11 @@ func (mmp minmaxPair) val(k mmpKey) *int {
 	}
 	return nil
 }
+
+func (mmp minmaxPair) copy() minmaxPair {
+	cp := make(minmaxPair)
+	for k, v := range mmp {
+		cp[k] = v
+	}
+	return cp
+}
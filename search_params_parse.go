@@ -0,0 +1,453 @@
+package narrow
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseSearchURL reconstructs search parameters from novel api or R18 api URL.
+// It returns *SearchParams or *SearchR18Params picked by endpoint path,
+// `ToURL` of the result equals the canonical form of u.
+func ParseSearchURL(u *url.URL) (Params, error) {
+	if u == nil {
+		return nil, ErrUnknownEndPoint
+	}
+
+	var params Params
+	var base *SearchParams
+	var r18 *SearchR18Params
+	switch {
+	case sameEndPointPath(u.Path, NarouAPIEndPoint):
+		base = NewSearchParams()
+		params = base
+	case sameEndPointPath(u.Path, NarouR18APIEndPoint):
+		r18 = NewSearchR18Params()
+		base = &r18.SearchParams
+		params = r18
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEndPoint, u.Path)
+	}
+
+	q := u.Query()
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var errs paramErrors
+	for _, k := range keys {
+		vs := q[k]
+		if len(vs) != 1 {
+			errs.add(k, strings.Join(vs, ","), ErrConflict)
+			continue
+		}
+		v := vs[0]
+		var err error
+		if parse, ok := searchQueryParsers[k]; ok {
+			err = parse(base, v)
+		} else if parse, ok := r18QueryParsers[k]; ok && r18 != nil {
+			err = parse(r18, v)
+		} else {
+			err = ErrUnsupported
+		}
+		if err != nil {
+			errs.add(k, v, err)
+		}
+	}
+	if ok, err := errs.result(); !ok {
+		return nil, err
+	}
+
+	if ok, err := params.(interface{ Valid() (bool, error) }).Valid(); !ok {
+		return nil, err
+	}
+	return params, nil
+}
+
+func sameEndPointPath(path, endPoint string) bool {
+	u, err := url.Parse(endPoint)
+	if err != nil {
+		return false
+	}
+	return strings.TrimSuffix(path, "/") == strings.TrimSuffix(u.Path, "/")
+}
+
+type fromQueryFunc func(params *SearchParams, v string) error
+
+var searchQueryParsers = map[string]fromQueryFunc{
+	outputFormatKey: (*SearchParams).fromQueryOutputFormat,
+	"st":            (*SearchParams).fromQueryStart,
+	"lim":           (*SearchParams).fromQueryLimit,
+	"order":         (*SearchParams).fromQueryOrder,
+	keyOutputField:  (*SearchParams).fromQueryOutputField,
+	keyWord:         (*SearchParams).fromQueryWord,
+	keyNotWord:      (*SearchParams).fromQueryNotWord,
+	"title":         searchFieldParser(SearchFieldTitle),
+	"ex":            searchFieldParser(SearchFieldStory),
+	"keyword":       searchFieldParser(SearchFieldKeyword),
+	"wname":         searchFieldParser(SearchFieldWriter),
+	keyBigGenre:     (*SearchParams).fromQueryBigGenre,
+	keyNotBigGenre:  (*SearchParams).fromQueryNotBigGenre,
+	keyGenre:        (*SearchParams).fromQueryGenre,
+	keyNotGenre:     (*SearchParams).fromQueryNotGenre,
+	keyUserID:       (*SearchParams).fromQueryUserID,
+	"isr15":         requiredKeywordParser(requiredKeywordIsR15),
+	"notr15":        requiredKeywordParser(requiredKeywordIsNotR15),
+	"isbl":          requiredKeywordParser(requiredKeywordIsBL),
+	"notbl":         requiredKeywordParser(requiredKeywordIsNotBL),
+	"isgl":          requiredKeywordParser(requiredKeywordIsGL),
+	"notgl":         requiredKeywordParser(requiredKeywordIsNotGL),
+	"iszankoku":     requiredKeywordParser(requiredKeywordIsZankoku),
+	"notzankoku":    requiredKeywordParser(requiredKeywordIsNotZankoku),
+	"istensei":      requiredKeywordParser(requiredKeywordIsTensei),
+	"nottensei":     requiredKeywordParser(requiredKeywordIsNotTensei),
+	"istenni":       requiredKeywordParser(requiredKeywordIsTenni),
+	"nottenni":      requiredKeywordParser(requiredKeywordIsNotTenni),
+	"istt":          requiredKeywordParser(requiredKeywordIsTT),
+	keyLength:       minmaxParser(func(params *SearchParams) *minmaxPair { return &params.lengths }),
+	"kaiwaritu":     minmaxParser(func(params *SearchParams) *minmaxPair { return &params.kaiwaritus }),
+	keySasie:        minmaxParser(func(params *SearchParams) *minmaxPair { return &params.sasies }),
+	keyReadTime:     minmaxParser(func(params *SearchParams) *minmaxPair { return &params.readTimes }),
+	keyNCode:        (*SearchParams).fromQueryNCode,
+	keyNovelState:   (*SearchParams).fromQueryNovelState,
+	keyBuntai:       (*SearchParams).fromQueryBuntai,
+	keyStop:         (*SearchParams).fromQueryStop,
+	keyIsPickup:     (*SearchParams).fromQueryPickup,
+	keyLastUp:       (*SearchParams).fromQueryLastUp,
+	"opt":           (*SearchParams).fromQueryOpt,
+	keyGzip:         (*SearchParams).fromQueryGzip,
+}
+
+func (params *SearchParams) fromQueryOutputFormat(v string) error {
+	if v != outputFormat {
+		return ErrUnsupported
+	}
+	return nil
+}
+
+func (params *SearchParams) fromQueryStart(v string) error {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return ErrUnknownValue
+	}
+	params.offset = n
+	return nil
+}
+
+func (params *SearchParams) fromQueryLimit(v string) error {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return ErrUnknownValue
+	}
+	params.limit = n
+	return nil
+}
+
+func (params *SearchParams) fromQueryGzip(v string) error {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return ErrUnknownValue
+	}
+	params.gzip = n
+	return nil
+}
+
+func (params *SearchParams) fromQueryOrder(v string) error {
+	for item, name := range orderItemNames {
+		if name == v {
+			params.order = item
+			return nil
+		}
+	}
+	return ErrUnknownValue
+}
+
+func (params *SearchParams) fromQueryOutputField(v string) error {
+	names := strings.Split(v, "-")
+	fields := make([]OutputField, 0, len(names))
+	for _, name := range names {
+		found := false
+		for f, short := range outputFieldShortNames {
+			if short != "" && short == name {
+				fields = append(fields, f)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ErrUnknownValue
+		}
+	}
+	params.AddOutputFields(fields)
+	return nil
+}
+
+func (params *SearchParams) fromQueryWord(v string) error {
+	params.AddWords(strings.Fields(v))
+	return nil
+}
+
+func (params *SearchParams) fromQueryNotWord(v string) error {
+	params.AddNotWords(strings.Fields(v))
+	return nil
+}
+
+// searchFieldParser parses `title=1`, `ex=1`, `keyword=1`, `wname=1`
+func searchFieldParser(field SearchField) fromQueryFunc {
+	return func(params *SearchParams, v string) error {
+		switch v {
+		case "0":
+		case "1":
+			params.AddSearchFields([]SearchField{field})
+		default:
+			return ErrUnknownValue
+		}
+		return nil
+	}
+}
+
+func (params *SearchParams) fromQueryBigGenre(v string) error {
+	codes, err := splitInts(v)
+	if err != nil {
+		return err
+	}
+	genres := make([]BigGenre, len(codes))
+	for i, c := range codes {
+		genres[i] = BigGenre(c)
+	}
+	params.AddBigGenres(genres)
+	return nil
+}
+
+func (params *SearchParams) fromQueryNotBigGenre(v string) error {
+	codes, err := splitInts(v)
+	if err != nil {
+		return err
+	}
+	genres := make([]BigGenre, len(codes))
+	for i, c := range codes {
+		genres[i] = BigGenre(c)
+	}
+	params.AddNotBigGenres(genres)
+	return nil
+}
+
+func (params *SearchParams) fromQueryGenre(v string) error {
+	codes, err := splitInts(v)
+	if err != nil {
+		return err
+	}
+	genres := make([]Genre, len(codes))
+	for i, c := range codes {
+		genres[i] = Genre(c)
+	}
+	params.AddGenres(genres)
+	return nil
+}
+
+func (params *SearchParams) fromQueryNotGenre(v string) error {
+	codes, err := splitInts(v)
+	if err != nil {
+		return err
+	}
+	genres := make([]Genre, len(codes))
+	for i, c := range codes {
+		genres[i] = Genre(c)
+	}
+	params.AddNotGenres(genres)
+	return nil
+}
+
+func (params *SearchParams) fromQueryUserID(v string) error {
+	ids, err := splitInts(v)
+	if err != nil {
+		return err
+	}
+	params.AddUserIDs(ids)
+	return nil
+}
+
+func (params *SearchParams) fromQueryNCode(v string) error {
+	params.AddNCodes(strings.Split(v, "-"))
+	return nil
+}
+
+func (params *SearchParams) fromQueryNovelState(v string) error {
+	for state, name := range novelStateShortNames {
+		if name != "" && name == v {
+			params.state = state
+			return nil
+		}
+	}
+	return ErrUnknownValue
+}
+
+func (params *SearchParams) fromQueryBuntai(v string) error {
+	codes, err := splitInts(v)
+	if err != nil {
+		return err
+	}
+	buntais := make([]Buntai, len(codes))
+	for i, c := range codes {
+		buntais[i] = Buntai(c)
+	}
+	params.AddBuntais(buntais)
+	return nil
+}
+
+func (params *SearchParams) fromQueryStop(v string) error {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return ErrUnknownValue
+	}
+	params.stopState = StopState(n)
+	return nil
+}
+
+func (params *SearchParams) fromQueryPickup(v string) error {
+	switch v {
+	case "0":
+		params.pickupState = PickupStateNot
+	case "1":
+		params.pickupState = PickupStatePickup
+	default:
+		return ErrUnknownValue
+	}
+	return nil
+}
+
+func (params *SearchParams) fromQueryLastUp(v string) error {
+	for ltype, name := range lastUpTypeNames {
+		if name == v {
+			params.SetLastUp(ltype)
+			return nil
+		}
+	}
+	stamps := strings.Split(v, "-")
+	if len(stamps) != 2 {
+		return ErrUnknownValue
+	}
+	start, err := strconv.ParseInt(stamps[0], 10, 64)
+	if err != nil {
+		return ErrUnknownValue
+	}
+	end, err := strconv.ParseInt(stamps[1], 10, 64)
+	if err != nil {
+		return ErrUnknownValue
+	}
+	params.SetLastUpTerm(time.Unix(start, 0), time.Unix(end, 0))
+	return nil
+}
+
+func (params *SearchParams) fromQueryOpt(v string) error {
+	if v != "weekly" {
+		return ErrUnknownValue
+	}
+	params.SetWithWeeklyUnique(true)
+	return nil
+}
+
+// requiredKeywordParser set flag directly so that conflicting flags are reported by Valid
+func requiredKeywordParser(kw requiredKeyword) fromQueryFunc {
+	return func(params *SearchParams, v string) error {
+		switch v {
+		case "0":
+		case "1":
+			params.requiredKeywordFlags[kw] = true
+		default:
+			return ErrUnknownValue
+		}
+		return nil
+	}
+}
+
+// minmaxParser parses `N`, `N-M`, `N-` and `-M` form
+func minmaxParser(target func(params *SearchParams) *minmaxPair) fromQueryFunc {
+	return func(params *SearchParams, v string) error {
+		mmp := make(minmaxPair)
+		pair := strings.Split(v, "-")
+		switch len(pair) {
+		case 1:
+			n, err := strconv.Atoi(pair[0])
+			if err != nil {
+				return ErrUnknownValue
+			}
+			mmp[mmpSingle] = n
+		case 2:
+			if pair[0] == "" && pair[1] == "" {
+				return ErrUnknownValue
+			}
+			for i, k := range []mmpKey{mmpMin, mmpMax} {
+				if pair[i] == "" {
+					continue
+				}
+				n, err := strconv.Atoi(pair[i])
+				if err != nil {
+					return ErrUnknownValue
+				}
+				mmp[k] = n
+			}
+		default:
+			return ErrUnknownValue
+		}
+		*target(params) = mmp
+		return nil
+	}
+}
+
+func splitInts(v string) ([]int, error) {
+	strs := strings.Split(v, "-")
+	ns := make([]int, len(strs))
+	for i, s := range strs {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, ErrUnknownValue
+		}
+		ns[i] = n
+	}
+	return ns, nil
+}
+
+type fromR18QueryFunc func(params *SearchR18Params, v string) error
+
+var r18QueryParsers = map[string]fromR18QueryFunc{
+	keyNocGenre:    (*SearchR18Params).fromQueryNocGenre,
+	keyNotNocGenre: (*SearchR18Params).fromQueryNotNocGenre,
+	keyXID:         (*SearchR18Params).fromQueryXID,
+}
+
+func (params *SearchR18Params) fromQueryNocGenre(v string) error {
+	codes, err := splitInts(v)
+	if err != nil {
+		return err
+	}
+	genres := make([]NocGenre, len(codes))
+	for i, c := range codes {
+		genres[i] = NocGenre(c)
+	}
+	params.AddNocGenres(genres)
+	return nil
+}
+
+func (params *SearchR18Params) fromQueryNotNocGenre(v string) error {
+	codes, err := splitInts(v)
+	if err != nil {
+		return err
+	}
+	genres := make([]NocGenre, len(codes))
+	for i, c := range codes {
+		genres[i] = NocGenre(c)
+	}
+	params.AddNotNocGenres(genres)
+	return nil
+}
+
+func (params *SearchR18Params) fromQueryXID(v string) error {
+	params.AddXIDs(strings.Split(v, "-"))
+	return nil
+}
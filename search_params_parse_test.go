@@ -0,0 +1,227 @@
+package narrow
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseSearchURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		want    string
+		wantR18 bool
+		wantErr error
+	}{
+		{"default",
+			"https://api.syosetu.com/novelapi/api/",
+			"https://api.syosetu.com/novelapi/api/?out=json", false, nil},
+		{"r18 default",
+			"https://api.syosetu.com/novel18api/api/?out=json",
+			"https://api.syosetu.com/novel18api/api/?out=json", true, nil},
+		{"canonical order",
+			"https://api.syosetu.com/novelapi/api/?word=%E7%95%B0%E4%B8%96%E7%95%8C&order=hyoka&lim=20&out=json&biggenre=1-2&genre=101&length=1000-&lastup=thisweek&of=t-n",
+			"https://api.syosetu.com/novelapi/api/?biggenre=1-2&genre=101&lastup=thisweek&length=1000-&lim=20&of=t-n&order=hyoka&out=json&word=%E7%95%B0%E4%B8%96%E7%95%8C", false, nil},
+		{"full width space separated words",
+			"https://api.syosetu.com/novelapi/api/?word=a%E3%80%80b",
+			"https://api.syosetu.com/novelapi/api/?out=json&word=a+b", false, nil},
+		{"search fields, required keywords",
+			"https://api.syosetu.com/novelapi/api/?title=1&wname=1&isr15=1&notbl=1&istensei=1",
+			"https://api.syosetu.com/novelapi/api/?isr15=1&istensei=1&notbl=1&out=json&title=1&wname=1", false, nil},
+		{"r18 nocgenre",
+			"https://api.syosetu.com/novel18api/api/?nocgenre=1-4&notnocgenre=2",
+			"https://api.syosetu.com/novel18api/api/?nocgenre=1-4&notnocgenre=2&out=json", true, nil},
+		{"r18 xid",
+			"https://api.syosetu.com/novel18api/api/?xid=x1234a-x56b&of=n-xid",
+			"https://api.syosetu.com/novel18api/api/?of=n-xid&out=json&xid=x1234a-x56b", true, nil},
+		{"xid on general api", "https://api.syosetu.com/novelapi/api/?xid=x1234a", "", false, ErrUnsupported},
+		{"xid output on general api", "https://api.syosetu.com/novelapi/api/?of=n-xid", "", false, ErrUnsupported},
+		{"userid on r18 api", "https://api.syosetu.com/novel18api/api/?userid=123", "", false, ErrUnsupported},
+		{"lastup timestamps",
+			"https://api.syosetu.com/novelapi/api/?lastup=1000-2000",
+			"https://api.syosetu.com/novelapi/api/?lastup=1000-2000&out=json", false, nil},
+		{"unknown endpoint", "https://api.syosetu.com/rank/rankget/", "", false, ErrUnknownEndPoint},
+		{"unknown key", "https://api.syosetu.com/novelapi/api/?foo=1", "", false, ErrUnsupported},
+		{"nocgenre on general api", "https://api.syosetu.com/novelapi/api/?nocgenre=1", "", false, ErrUnsupported},
+		{"yaml output", "https://api.syosetu.com/novelapi/api/?out=yaml", "", false, ErrUnsupported},
+		{"unknown order", "https://api.syosetu.com/novelapi/api/?order=foo", "", false, ErrUnknownValue},
+		{"bad length", "https://api.syosetu.com/novelapi/api/?length=a-b", "", false, ErrUnknownValue},
+		{"duplicated key", "https://api.syosetu.com/novelapi/api/?lim=1&lim=2", "", false, ErrConflict},
+		{"invalid params", "https://api.syosetu.com/novelapi/api/?length=100-10", "", false, ErrInvalidRange},
+		{"conflicting flags", "https://api.syosetu.com/novelapi/api/?isr15=1&notr15=1", "", false, ErrConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSearchURL(parseURL(tt.url))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("ParseSearchURL() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("ParseSearchURL() error = %v", err)
+				return
+			}
+			if _, isR18 := got.(*SearchR18Params); isR18 != tt.wantR18 {
+				t.Errorf("ParseSearchURL() = %T, want R18 %v", got, tt.wantR18)
+			}
+			u, err := got.ToURL()
+			if err != nil {
+				t.Errorf("ParseSearchURL().ToURL() error = %v", err)
+				return
+			}
+			if u.String() != tt.want {
+				t.Errorf("ParseSearchURL().ToURL() = %v, want %v", u, tt.want)
+			}
+		})
+	}
+}
+
+var roundTripSetters = []func(params *SearchParams){
+	func(params *SearchParams) { params.SetLimit(500) },
+	func(params *SearchParams) { params.SetStart(42) },
+	func(params *SearchParams) { params.SetOrder(OrderItemWeekly) },
+	func(params *SearchParams) { params.SetOrder(OrderItemOld) },
+	func(params *SearchParams) {
+		params.AddOutputFields([]OutputField{OutputFieldTitle, OutputFieldNovelType, OutputFieldImpressionCount})
+	},
+	func(params *SearchParams) { params.AddWords([]string{"異世界", "恋愛"}) },
+	func(params *SearchParams) { params.AddNotWords([]string{"ハーレム"}) },
+	func(params *SearchParams) {
+		params.AddSearchFields([]SearchField{SearchFieldTitle, SearchFieldKeyword})
+	},
+	func(params *SearchParams) { params.AddSearchFields([]SearchField{SearchFieldStory, SearchFieldWriter}) },
+	func(params *SearchParams) { params.AddBigGenres([]BigGenre{BigGenreFantasy, BigGenreSF}) },
+	func(params *SearchParams) { params.AddNotBigGenres([]BigGenre{BigGenreOther}) },
+	func(params *SearchParams) { params.AddGenres([]Genre{GenreFantasyHighFantasy, GenreSFSpace}) },
+	func(params *SearchParams) { params.AddNotGenres([]Genre{GenreBungeiHorror}) },
+	func(params *SearchParams) { params.AddUserIDs([]int{123, 456}) },
+	func(params *SearchParams) { params.SetIsR15(true) },
+	func(params *SearchParams) { params.SetIsNotR15(true) },
+	func(params *SearchParams) { params.SetIsBL(true) },
+	func(params *SearchParams) { params.SetIsNotBL(true) },
+	func(params *SearchParams) { params.SetIsGL(true) },
+	func(params *SearchParams) { params.SetIsNotGL(true) },
+	func(params *SearchParams) { params.SetIsZankoku(true) },
+	func(params *SearchParams) { params.SetIsNotZankoku(true) },
+	func(params *SearchParams) { params.SetIsTensei(true) },
+	func(params *SearchParams) { params.SetIsNotTensei(true) },
+	func(params *SearchParams) { params.SetIsTenni(true) },
+	func(params *SearchParams) { params.SetIsNotTenni(true) },
+	func(params *SearchParams) { params.SetIsTT(true) },
+	func(params *SearchParams) { params.SetLength(1000) },
+	func(params *SearchParams) { params.SetMinLength(100) },
+	func(params *SearchParams) { params.SetMaxLength(100000) },
+	func(params *SearchParams) { params.SetKaiwaritu(30) },
+	func(params *SearchParams) { params.SetMinKaiwaritu(10) },
+	func(params *SearchParams) { params.SetMaxKaiwaritu(90) },
+	func(params *SearchParams) { params.SetSasie(1) },
+	func(params *SearchParams) { params.SetMinSasie(2) },
+	func(params *SearchParams) { params.SetMaxSasie(10) },
+	func(params *SearchParams) { params.SetReadTime(60) },
+	func(params *SearchParams) { params.SetMinReadTime(5) },
+	func(params *SearchParams) { params.SetMaxReadTime(600) },
+	func(params *SearchParams) { params.AddNCodes([]string{"N1234AB", "N0001A"}) },
+	func(params *SearchParams) { params.SetNovelState(NovelStateRensaiEnded) },
+	func(params *SearchParams) { params.SetNovelState(NovelStateShortAndRensaiEnded) },
+	func(params *SearchParams) {
+		params.AddBuntais([]Buntai{BuntaiIndentAverageEmptyLines, BuntaiNoIndentManyEmptyLines})
+	},
+	func(params *SearchParams) { params.SetStopState(StopStateExclude) },
+	func(params *SearchParams) { params.SetStopState(StopStateOnly) },
+	func(params *SearchParams) { params.SetPickupState(PickupStateNot) },
+	func(params *SearchParams) { params.SetPickupState(PickupStatePickup) },
+	func(params *SearchParams) { params.SetLastUp(LastUpTypeLastMonth) },
+	func(params *SearchParams) { params.SetLastUpTerm(time.Unix(1500000000, 0), time.Unix(1600000000, 0)) },
+	func(params *SearchParams) { params.SetWithWeeklyUnique(true) },
+	func(params *SearchParams) { params.SetGzip(5) },
+}
+
+var roundTripR18Setters = []func(params *SearchR18Params){
+	func(params *SearchR18Params) { params.AddNocGenres([]NocGenre{NocGenreMidnight, NocGenreNocturne}) },
+	func(params *SearchR18Params) { params.AddNotNocGenres([]NocGenre{NocGenreMoonlightBL}) },
+	func(params *SearchR18Params) { params.AddXIDs([]string{"x1234a", "X56B"}) },
+}
+
+func assertRoundTrip(t *testing.T, params Params) {
+	t.Helper()
+	u, err := params.ToURL()
+	if err != nil {
+		t.Errorf("%T.ToURL() error = %v", params, err)
+		return
+	}
+	parsed, err := ParseSearchURL(u)
+	if err != nil {
+		t.Errorf("ParseSearchURL(%v) error = %v", u, err)
+		return
+	}
+	if reflect.TypeOf(parsed) != reflect.TypeOf(params) {
+		t.Errorf("ParseSearchURL(%v) = %T, want %T", u, parsed, params)
+		return
+	}
+	got, err := parsed.ToURL()
+	if err != nil {
+		t.Errorf("ParseSearchURL(%v).ToURL() error = %v", u, err)
+		return
+	}
+	if got.String() != u.String() {
+		t.Errorf("ParseSearchURL(%v).ToURL() = %v", u, got)
+	}
+}
+
+func TestParseSearchURL_roundTrip(t *testing.T) {
+	for i, set := range roundTripSetters {
+		params := NewSearchParams()
+		set(params)
+		if _, err := params.ToURL(); err != nil {
+			t.Errorf("setter %d makes invalid params: %v", i, err)
+		}
+		assertRoundTrip(t, params)
+	}
+
+	// random combinations, a setter making contradictory condition is skipped so params stay valid
+	rnd := rand.New(rand.NewSource(1))
+	for n := 0; n < 1000; n++ {
+		params := NewSearchParams()
+		r18 := NewSearchR18Params()
+		for _, set := range roundTripSetters {
+			if rnd.Intn(4) == 0 {
+				params = applyIfValid(params, set)
+			}
+			if rnd.Intn(4) == 0 {
+				r18 = applyR18IfValid(r18, func(p *SearchR18Params) { set(&p.SearchParams) })
+			}
+		}
+		for _, set := range roundTripR18Setters {
+			if rnd.Intn(2) == 0 {
+				r18 = applyR18IfValid(r18, set)
+			}
+		}
+		assertRoundTrip(t, params)
+		assertRoundTrip(t, r18)
+	}
+}
+
+// applyIfValid returns params with set applied, or params itself if set makes it invalid
+func applyIfValid(params *SearchParams, set func(*SearchParams)) *SearchParams {
+	cp := params.copy()
+	set(cp)
+	if ok, _ := cp.Valid(); !ok {
+		return params
+	}
+	return cp
+}
+
+// applyR18IfValid returns params with set applied, or params itself if set makes it invalid
+func applyR18IfValid(params *SearchR18Params, set func(*SearchR18Params)) *SearchR18Params {
+	cp := params.clone().(*SearchR18Params)
+	set(cp)
+	if ok, _ := cp.Valid(); !ok {
+		return params
+	}
+	return cp
+}
@@ -1,6 +1,7 @@
 package narrow
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"reflect"
@@ -49,6 +50,9 @@ func TestSearchParams_ToURL(t *testing.T) {
 		{"with output field Title, NCode",
 			&SearchParams{outputFields: []OutputField{OutputFieldTitle, OutputFieldNCode}},
 			parseURL("https://api.syosetu.com/novelapi/api/?out=json&of=t-n"), false},
+		{"with order",
+			&SearchParams{order: OrderItemHyoka},
+			parseURL("https://api.syosetu.com/novelapi/api/?out=json&order=hyoka"), false},
 		{"with output field Title, NCode, ImpressionCount",
 			&SearchParams{outputFields: []OutputField{OutputFieldTitle, OutputFieldNCode, OutputFieldImpressionCount}},
 			parseURL("https://api.syosetu.com/novelapi/api/?out=json&of=t-n-imp"), false},
@@ -89,8 +93,59 @@ func TestSearchParams_Valid(t *testing.T) {
 		params  *SearchParams
 		want    bool
 		wantErr bool
-	}{
-	// TODO: Add test cases.
+		wantIs  []error
+	}{
+		{"nil params", nil, true, false, nil},
+		{"all default params", &SearchParams{}, true, false, nil},
+		{"new params", NewSearchParams(), true, false, nil},
+		{"r15 and not r15",
+			&SearchParams{requiredKeywordFlags: map[requiredKeyword]bool{requiredKeywordIsR15: true, requiredKeywordIsNotR15: true}},
+			false, true, []error{ErrConflict}},
+		{"tt with not tensei and not tenni",
+			&SearchParams{requiredKeywordFlags: map[requiredKeyword]bool{requiredKeywordIsTT: true, requiredKeywordIsNotTensei: true, requiredKeywordIsNotTenni: true}},
+			false, true, []error{ErrConflict}},
+		{"min length less than max length",
+			&SearchParams{lengths: minmaxPair{mmpMin: 100, mmpMax: 1000}},
+			true, false, nil},
+		{"min length greater than max length",
+			&SearchParams{lengths: minmaxPair{mmpMin: 1000, mmpMax: 100}},
+			false, true, []error{ErrInvalidRange}},
+		{"length with min length",
+			&SearchParams{lengths: minmaxPair{mmpSingle: 100, mmpMin: 10}},
+			false, true, []error{ErrConflict}},
+		{"negative sasie",
+			&SearchParams{sasies: minmaxPair{mmpMin: -1}},
+			false, true, []error{ErrOutOfRange}},
+		{"kaiwaritu over 100",
+			&SearchParams{kaiwaritus: minmaxPair{mmpMax: 101}},
+			false, true, []error{ErrOutOfRange}},
+		{"limit over 500", &SearchParams{limit: 501}, false, true, []error{ErrOutOfRange}},
+		{"xid output is r18 only",
+			&SearchParams{outputFields: []OutputField{OutputFieldNCode, OutputFieldXID}},
+			false, true, []error{ErrUnsupported}},
+		{"offset over 2000", &SearchParams{offset: 2001}, false, true, []error{ErrOutOfRange}},
+		{"genre belongs to big genre",
+			&SearchParams{bigGenres: []BigGenre{BigGenreRenai}, genres: []Genre{GenreRenaiIsekai}},
+			true, false, nil},
+		{"genre does not belong to big genre",
+			&SearchParams{bigGenres: []BigGenre{BigGenreRenai}, genres: []Genre{GenreSFSpace}},
+			false, true, []error{ErrGenreMismatch}},
+		{"genre belongs to not big genre",
+			&SearchParams{notBigGenres: []BigGenre{BigGenreSF}, genres: []Genre{GenreSFSpace}},
+			false, true, []error{ErrConflict}},
+		{"genre and not genre",
+			&SearchParams{genres: []Genre{GenreSFSpace}, notGenres: []Genre{GenreSFSpace}},
+			false, true, []error{ErrConflict}},
+		{"unknown genre", &SearchParams{genres: []Genre{Genre(42)}}, false, true, []error{ErrUnknownValue}},
+		{"valid ncodes", &SearchParams{ncodes: []string{"N0000A", "n1234ab"}}, true, false, nil},
+		{"bad ncode", &SearchParams{ncodes: []string{"N0000A", "12345"}}, false, true, []error{ErrInvalidNCode}},
+		{"lastup start after end",
+			&SearchParams{lastUp: LastUpTypeTimeStamp, lastUpStart: time.Unix(2000, 0), lastUpEnd: time.Unix(1000, 0)},
+			false, true, []error{ErrInvalidRange}},
+		{"unknown order", &SearchParams{order: OrderItem(-1)}, false, true, []error{ErrUnknownValue}},
+		{"multiple errors",
+			&SearchParams{limit: 1000, lengths: minmaxPair{mmpMin: 1000, mmpMax: 100}, ncodes: []string{"x"}},
+			false, true, []error{ErrOutOfRange, ErrInvalidRange, ErrInvalidNCode}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -102,10 +157,29 @@ func TestSearchParams_Valid(t *testing.T) {
 			if got != tt.want {
 				t.Errorf("SearchParams.Valid() = %v, want %v", got, tt.want)
 			}
+			for _, target := range tt.wantIs {
+				if !errors.Is(err, target) {
+					t.Errorf("SearchParams.Valid() error = %v, want errors.Is %v", err, target)
+				}
+			}
+			var verr *ValidationError
+			if tt.wantErr && (!errors.As(err, &verr) || len(verr.Errors) != len(tt.wantIs)) {
+				t.Errorf("SearchParams.Valid() error = %v, want %d *ParamError", err, len(tt.wantIs))
+			}
 		})
 	}
 }
 
+func TestSearchParams_ToURL_invalid(t *testing.T) {
+	params := NewSearchParams()
+	params.SetMinLength(1000)
+	params.SetMaxLength(100)
+	u, err := params.ToURL()
+	if u != nil || !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("SearchParams.ToURL() = %v, %v, want nil, ErrInvalidRange", u, err)
+	}
+}
+
 func TestSearchParams_Limit(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -176,6 +250,58 @@ func TestSearchParams_queryFromLimit(t *testing.T) {
 	}
 }
 
+func TestSearchParams_SetGzip(t *testing.T) {
+	type args struct {
+		level int
+	}
+	tests := []struct {
+		name   string
+		params *SearchParams
+		args   args
+		want   int
+	}{
+		{"set 5 ok", &SearchParams{}, args{5}, 5},
+		{"should not set < 1", &SearchParams{gzip: 3}, args{0}, 3},
+		{"should not set > 5", &SearchParams{gzip: 3}, args{6}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params.SetGzip(tt.args.level)
+			if tt.params.Gzip() != tt.want {
+				t.Errorf("SearchParams.SetGzip(%v) should be change gzip %v, but %v", tt.args.level, tt.want, tt.params.Gzip())
+			}
+		})
+	}
+}
+
+func TestSearchParams_ClearGzip(t *testing.T) {
+	params := &SearchParams{}
+	params.SetGzip(5)
+
+	params.ClearGzip()
+	if params.Gzip() != 0 {
+		t.Errorf("SearchParams.ClearGzip() should change gzip be 0, but %v", params.Gzip())
+	}
+}
+
+func TestSearchParams_queryFromGzip(t *testing.T) {
+	tests := []struct {
+		name   string
+		params *SearchParams
+		want   url.Values
+	}{
+		{"no gzip should be no query", &SearchParams{}, makeValues([][2]string{})},
+		{"gzip:X to gzip=X", &SearchParams{gzip: 5}, makeValues([][2]string{{"gzip", "5"}})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.params.queryFromGzip(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SearchParams.queryFromGzip() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestSearchParams_Start(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -1703,6 +1829,9 @@ func TestSearchParams_queryFromRequiredKeywords(t *testing.T) {
 		{"is not r15",
 			&SearchParams{requiredKeywordFlags: map[requiredKeyword]bool{requiredKeywordIsNotR15: true}},
 			makeValues([][2]string{{"notr15", "1"}})},
+		{"is tensei",
+			&SearchParams{requiredKeywordFlags: map[requiredKeyword]bool{requiredKeywordIsTensei: true}},
+			makeValues([][2]string{{"istensei", "1"}})},
 		// TODO: more flags
 	}
 	for _, tt := range tests {
@@ -2466,6 +2595,9 @@ func TestSearchParams_AddNCodes(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.params.AddNCodes(tt.args.ncodes)
+			if !reflect.DeepEqual(tt.params.ncodes, tt.want) {
+				t.Errorf("SearchParams.AddNCodes(%v) should change ncodes %v, but %v", tt.args.ncodes, tt.want, tt.params.ncodes)
+			}
 		})
 	}
 }
@@ -3,9 +3,12 @@ package narrow
 import (
 	"fmt"
 	"net/url"
+	"regexp"
 	"strings"
 )
 
+var xidRe = regexp.MustCompile(`^x[0-9a-z]+$`)
+
 // NewSearchR18Params return new search r18 api parameter object
 func NewSearchR18Params() *SearchR18Params {
 	params := &SearchR18Params{}
@@ -15,7 +18,56 @@ func NewSearchR18Params() *SearchR18Params {
 
 // ToURL return full URL or nil if params contains invalid condition
 func (params *SearchR18Params) ToURL() (*url.URL, error) {
-	return params.makeFullURL(params.endPointURL(), params.toQueryFuncs())
+	return params.makeFullURL(params.endPointURL(), params.Valid, params.toQueryFuncs())
+}
+
+// Valid returns params is OK or not, error is *ValidationError listing every violated rule
+func (params *SearchR18Params) Valid() (bool, error) {
+	if params == nil {
+		return true, nil
+	}
+	errs := params.validateCommon()
+
+	// R18 api has no genre, use nocgenre instead
+	for _, g := range params.bigGenres {
+		errs.add(keyBigGenre, int(g), ErrUnsupported)
+	}
+	for _, g := range params.notBigGenres {
+		errs.add(keyNotBigGenre, int(g), ErrUnsupported)
+	}
+	for _, g := range params.genres {
+		errs.add(keyGenre, int(g), ErrUnsupported)
+	}
+	for _, g := range params.notGenres {
+		errs.add(keyNotGenre, int(g), ErrUnsupported)
+	}
+	// R18 api ignores userid, use xid instead
+	for _, id := range params.userIDs {
+		errs.add(keyUserID, id, ErrUnsupported)
+	}
+
+	ngs := make(map[NocGenre]bool)
+	for _, g := range params.nocGenres {
+		if g < NocGenreNocturne || g > NocGenreMidnight {
+			errs.add(keyNocGenre, int(g), ErrUnknownValue)
+		}
+		ngs[g] = true
+	}
+	for _, g := range params.notNocGenres {
+		if g < NocGenreNocturne || g > NocGenreMidnight {
+			errs.add(keyNotNocGenre, int(g), ErrUnknownValue)
+		}
+		if ngs[g] {
+			errs.add(keyNotNocGenre, int(g), ErrConflict)
+		}
+	}
+	for _, x := range params.xids {
+		if !xidRe.MatchString(x) {
+			errs.add(keyXID, x, ErrUnknownValue)
+		}
+	}
+
+	return errs.result()
 }
 
 func (params *SearchR18Params) endPointURL() string {
@@ -33,6 +85,7 @@ func (params *SearchR18Params) toQueryFuncs() []toQueryFunc {
 		params.queryFromNocGenre,
 		params.queryFromNotNocGenre,
 		params.queryFromUserID,
+		params.queryFromXID,
 		params.queryFromRequiredKeywords,
 		params.queryFromLength,
 		params.queryFromKaiwaritu,
@@ -45,6 +98,8 @@ func (params *SearchR18Params) toQueryFuncs() []toQueryFunc {
 		params.queryFromPickup,
 		params.queryFromLastUp,
 		params.queryFromOpt, // undocumented
+		params.queryFromOrder,
+		params.queryFromGzip,
 	}
 }
 
@@ -149,3 +204,27 @@ func (params *SearchR18Params) queryFromNotNocGenre() url.Values {
 	vs.Set(keyNotNocGenre, strings.Join(codes, "-"))
 	return vs
 }
+
+// XIDs returns `xid` parameter
+func (params *SearchR18Params) XIDs() []string { return params.xids }
+
+// AddXIDs add R18 author x-ids such as `x1234a`, use it instead of AddUserIDs
+func (params *SearchR18Params) AddXIDs(xids []string) {
+	normalized := make([]string, len(xids))
+	for i, x := range xids {
+		normalized[i] = strings.ToLower(strings.TrimSpace(x))
+	}
+	params.xids = appendUnique(params.xids, normalized)
+}
+
+// ClearXIDs clear x-id setting
+func (params *SearchR18Params) ClearXIDs() { params.xids = nil }
+
+func (params *SearchR18Params) queryFromXID() url.Values {
+	vs := make(url.Values)
+	if len(params.xids) == 0 {
+		return vs
+	}
+	vs.Set(keyXID, strings.Join(params.xids, "-"))
+	return vs
+}
@@ -1,6 +1,7 @@
 package narrow
 
 import (
+	"errors"
 	"net/url"
 	"reflect"
 	"testing"
@@ -50,6 +51,9 @@ func TestSearchR18Params_ToURL(t *testing.T) {
 		{"with output field Title, NCode",
 			&SearchR18Params{SearchParams: SearchParams{outputFields: []OutputField{OutputFieldTitle, OutputFieldNCode}}},
 			parseURL("https://api.syosetu.com/novel18api/api/?out=json&of=t-n"), false},
+		{"with order",
+			&SearchR18Params{SearchParams: SearchParams{order: OrderItemHyoka}},
+			parseURL("https://api.syosetu.com/novel18api/api/?out=json&order=hyoka"), false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -81,6 +85,55 @@ func TestSearchR18Params_ToURL(t *testing.T) {
 	}
 }
 
+func TestSearchR18Params_Valid(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  *SearchR18Params
+		want    bool
+		wantErr bool
+		wantIs  []error
+	}{
+		{"nil params", nil, true, false, nil},
+		{"new params", NewSearchR18Params(), true, false, nil},
+		{"nocgenre", &SearchR18Params{nocGenres: []NocGenre{NocGenreNocturne}}, true, false, nil},
+		{"nocgenre and not nocgenre",
+			&SearchR18Params{nocGenres: []NocGenre{NocGenreNocturne}, notNocGenres: []NocGenre{NocGenreNocturne}},
+			false, true, []error{ErrConflict}},
+		{"unknown nocgenre", &SearchR18Params{nocGenres: []NocGenre{NocGenre(5)}}, false, true, []error{ErrUnknownValue}},
+		{"xid", &SearchR18Params{xids: []string{"x1234a"}}, true, false, nil},
+		{"invalid xid", &SearchR18Params{xids: []string{"1234"}}, false, true, []error{ErrUnknownValue}},
+		{"genre is not supported",
+			&SearchR18Params{SearchParams: SearchParams{genres: []Genre{GenreSFSpace}}},
+			false, true, []error{ErrUnsupported}},
+		{"userid is not supported",
+			&SearchR18Params{SearchParams: SearchParams{userIDs: []int{123}}},
+			false, true, []error{ErrUnsupported}},
+		{"xid output",
+			&SearchR18Params{SearchParams: SearchParams{outputFields: []OutputField{OutputFieldXID}}},
+			true, false, nil},
+		{"common rules",
+			&SearchR18Params{SearchParams: SearchParams{lengths: minmaxPair{mmpMin: 1000, mmpMax: 100}}},
+			false, true, []error{ErrInvalidRange}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.params.Valid()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SearchR18Params.Valid() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("SearchR18Params.Valid() = %v, want %v", got, tt.want)
+			}
+			for _, target := range tt.wantIs {
+				if !errors.Is(err, target) {
+					t.Errorf("SearchR18Params.Valid() error = %v, want errors.Is %v", err, target)
+				}
+			}
+		})
+	}
+}
+
 func TestSearchR18Params_NocGenres(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -234,3 +287,19 @@ func TestSearchR18Params_queryFromNotNocGenre(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchR18Params_AddXIDs(t *testing.T) {
+	params := NewSearchR18Params()
+	params.AddXIDs([]string{"x1234a", " X56B "})
+	params.AddXIDs([]string{"x1234a"})
+	if want := []string{"x1234a", "x56b"}; !reflect.DeepEqual(params.XIDs(), want) {
+		t.Errorf("SearchR18Params.XIDs() = %v, want %v", params.XIDs(), want)
+	}
+	if got, want := params.queryFromXID(), makeValues([][2]string{{"xid", "x1234a-x56b"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("SearchR18Params.queryFromXID() = %v, want %v", got, want)
+	}
+	params.ClearXIDs()
+	if got := params.queryFromXID(); len(got) != 0 {
+		t.Errorf("SearchR18Params.queryFromXID() = %v, want empty", got)
+	}
+}
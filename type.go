@@ -32,6 +32,8 @@ type NovelInfo struct {
 
 	// Nocture or else, R18 only
 	NocturneGenre *int
+	// 作者の X-ID, R18 only
+	XID *string
 
 	//// 原作(未使用項目)
 	//// Gensaku string
@@ -155,6 +157,9 @@ const (
 	OutputFieldYearlyPoint
 
 	OutputFieldImpressionCount
+
+	// OutputFieldXID is R18 only
+	OutputFieldXID
 )
 
 // SearchField stands for search word field
@@ -356,6 +361,7 @@ type SearchParams struct {
 	limit  int
 	offset int
 	order  OrderItem
+	gzip   int
 
 	opts map[optionName]bool
 }
@@ -378,6 +384,7 @@ type SearchR18Params struct {
 
 	nocGenres    []NocGenre
 	notNocGenres []NocGenre
+	xids         []string
 }
 
 // FetchSite for site fetch from
@@ -399,7 +406,13 @@ type FetchParams struct {
 
 	WithContent bool
 
+	// Pages selects episodes to download, it takes precedence over WithContent and Page
+	Pages *PageSelection
+
 	AllowOver18 bool
+
+	// Concurrency is number of pages downloaded in parallel, 0 uses client setting
+	Concurrency int
 }
 
 // FetchResult contains fetch result
@@ -412,10 +425,33 @@ type FetchResult struct {
 	Title      string
 	WriterName string
 	Abstruct   string
+	// Chapters is table of contents from index, episodes before first chapter heading are in no chapter
+	Chapters []Chapter
+}
+
+// Chapter is chapter heading (`chapter_title`) in index and its episodes
+type Chapter struct {
+	Title       string
+	PageNumbers []int
 }
 
+// PageStatus is download status of a page content
+type PageStatus int
+
+// page statuses
+const (
+	// PageStatusSkipped page content is not requested, only index information is set
+	PageStatusSkipped PageStatus = iota
+	// PageStatusFetched page content is downloaded
+	PageStatusFetched
+	// PageStatusFailed page content download failed, FetchPage.Err has the reason
+	PageStatusFailed
+)
+
 // FetchPage contains fetched content
 type FetchPage struct {
+	Status         PageStatus
+	Err            error
 	rawHTML        string
 	Lines          []ContentLine
 	Preface        []ContentLine
@@ -431,4 +467,40 @@ type FetchPage struct {
 type ContentLine struct {
 	// RawLine contains raw content such as `<p id="L42">ほげほげ</p>`
 	RawLine string
+	// Number is line number from id `L42`, `Lp3` or `La1`, 0 if id is missing
+	Number int
+	// Text is plain text, ruby is replaced with its base and line break is `\n`
+	Text string
+	// Segments is typed parts of line, empty line (`<p><br></p>`) has none
+	Segments []Segment
+}
+
+// SegmentType is kind of Segment
+type SegmentType int
+
+// segment types
+const (
+	// SegmentText is plain text
+	SegmentText SegmentType = iota
+	// SegmentRuby is base text with reading, from `<ruby><rb>硯</rb><rt>すずり</rt></ruby>`
+	SegmentRuby
+	// SegmentEmphasis is 傍点 text, from dot ruby such as `<rt>・</rt>` or `<em>`
+	SegmentEmphasis
+	// SegmentBreak is line break in a line
+	SegmentBreak
+	// SegmentIllustration is embedded illustration (挿絵)
+	SegmentIllustration
+)
+
+// Segment is a part of ContentLine
+type Segment struct {
+	Type SegmentType
+	// Text is text of SegmentText, base of SegmentRuby, emphasized text of SegmentEmphasis
+	Text string
+	// Ruby is reading of SegmentRuby
+	Ruby string
+	// ImageURL is absolute image URL of SegmentIllustration
+	ImageURL string
+	// LinkURL is absolute URL the illustration links to, such as mitemin page
+	LinkURL string
 }
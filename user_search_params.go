@@ -0,0 +1,320 @@
+package narrow
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// NarouUserAPIEndPoint is Narou user api endpoint
+const NarouUserAPIEndPoint = "https://api.syosetu.com/userapi/api/"
+
+// UserSearchParams contains user (author) API search parameters
+type UserSearchParams struct {
+	limit     int
+	offset    int
+	gzip      int
+	order     UserOrderItem
+	words     []string
+	notWords  []string
+	userIDs   []int
+	name1st   string
+	minNovel  *int
+	maxNovel  *int
+	minReview *int
+	maxReview *int
+}
+
+// UserOrderItem is `order` of user api
+type UserOrderItem int
+
+const (
+	// UserOrderItemNew is ユーザIDの新しい順
+	UserOrderItemNew UserOrderItem = iota
+	// UserOrderItemOld is ユーザIDの古い順
+	UserOrderItemOld
+	// UserOrderItemNovelCount is 作品投稿数の多い順
+	UserOrderItemNovelCount
+	// UserOrderItemReviewCount is レビュー投稿数の多い順
+	UserOrderItemReviewCount
+	// UserOrderItemNovelLength is 小説累計文字数の多い順
+	UserOrderItemNovelLength
+	// UserOrderItemSumGlobalPoint is 総合評価ポイントの合計の多い順
+	UserOrderItemSumGlobalPoint
+)
+
+var userOrderItemNames = map[UserOrderItem]string{
+	UserOrderItemNew:            "new",
+	UserOrderItemOld:            "old",
+	UserOrderItemNovelCount:     "novelcnt",
+	UserOrderItemReviewCount:    "reviewcnt",
+	UserOrderItemNovelLength:    "novellength",
+	UserOrderItemSumGlobalPoint: "sumglobalpoint",
+}
+
+const (
+	keyName1st   = "name1st"
+	keyMinNovel  = "minnovel"
+	keyMaxNovel  = "maxnovel"
+	keyMinReview = "minreview"
+	keyMaxReview = "maxreview"
+)
+
+// name1stOther is `name1st` for names not starting with kana
+const name1stOther = "その他"
+
+// name1stKanas are kana initials which `name1st` accepts besides name1stOther
+const name1stKanas = "アイウエオカキクケコサシスセソタチツテトナニヌネノハヒフヘホマミムメモヤユヨラリルレロワ"
+
+func validName1st(name1st string) bool {
+	return name1st == name1stOther || utf8.RuneCountInString(name1st) == 1 && strings.Contains(name1stKanas, name1st)
+}
+
+// NewUserSearchParams return new user api parameter object
+func NewUserSearchParams() *UserSearchParams {
+	return &UserSearchParams{}
+}
+
+// ToURL return full URL or nil if params contains invalid condition
+func (params *UserSearchParams) ToURL() (*url.URL, error) {
+	fullURL, err := url.Parse(NarouUserAPIEndPoint)
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := params.Valid(); !ok {
+		return nil, err
+	}
+
+	q := fullURL.Query()
+	q.Set(outputFormatKey, outputFormat)
+	if params.offset != 0 {
+		q.Set("st", fmt.Sprintf("%d", params.offset))
+	}
+	if params.limit != 0 {
+		q.Set("lim", fmt.Sprintf("%d", params.limit))
+	}
+	if params.gzip != 0 {
+		q.Set(keyGzip, fmt.Sprintf("%d", params.gzip))
+	}
+	if params.order != UserOrderItemNew {
+		q.Set("order", userOrderItemNames[params.order])
+	}
+	if len(params.words) != 0 {
+		q.Set(keyWord, strings.Join(params.words, " "))
+	}
+	if len(params.notWords) != 0 {
+		q.Set(keyNotWord, strings.Join(params.notWords, " "))
+	}
+	if len(params.userIDs) != 0 {
+		ids := make([]string, len(params.userIDs))
+		for i, id := range params.userIDs {
+			ids[i] = fmt.Sprintf("%d", id)
+		}
+		q.Set(keyUserID, strings.Join(ids, "-"))
+	}
+	if params.name1st != "" {
+		q.Set(keyName1st, params.name1st)
+	}
+	for key, v := range map[string]*int{
+		keyMinNovel: params.minNovel, keyMaxNovel: params.maxNovel,
+		keyMinReview: params.minReview, keyMaxReview: params.maxReview,
+	} {
+		if v != nil {
+			q.Set(key, fmt.Sprintf("%d", *v))
+		}
+	}
+	fullURL.RawQuery = q.Encode()
+	return fullURL, nil
+}
+
+// Valid returns params is OK or not, error is *ValidationError listing every violated rule
+func (params *UserSearchParams) Valid() (bool, error) {
+	if params == nil {
+		return true, nil
+	}
+	var errs paramErrors
+	if params.limit != 0 && (params.limit < minLimit || params.limit > maxLimit) {
+		errs.add("lim", params.limit, ErrOutOfRange)
+	}
+	if params.offset != 0 && (params.offset < minOffset || params.offset > maxOffset) {
+		errs.add("st", params.offset, ErrOutOfRange)
+	}
+	if params.gzip != 0 && (params.gzip < minGzip || params.gzip > maxGzip) {
+		errs.add(keyGzip, params.gzip, ErrOutOfRange)
+	}
+	if _, ok := userOrderItemNames[params.order]; !ok {
+		errs.add("order", int(params.order), ErrUnknownValue)
+	}
+	nots := make(map[string]bool)
+	for _, w := range params.notWords {
+		nots[w] = true
+	}
+	for _, w := range params.words {
+		if nots[w] {
+			errs.add(keyNotWord, w, ErrConflict)
+		}
+	}
+	if params.name1st != "" && !validName1st(params.name1st) {
+		errs.add(keyName1st, params.name1st, ErrUnknownValue)
+	}
+	validateMinMax(&errs, keyMinNovel, keyMaxNovel, params.minNovel, params.maxNovel)
+	validateMinMax(&errs, keyMinReview, keyMaxReview, params.minReview, params.maxReview)
+	return errs.result()
+}
+
+func validateMinMax(errs *paramErrors, minKey, maxKey string, minV, maxV *int) {
+	if minV != nil && *minV < 0 {
+		errs.add(minKey, *minV, ErrOutOfRange)
+	}
+	if maxV != nil && *maxV < 0 {
+		errs.add(maxKey, *maxV, ErrOutOfRange)
+	}
+	if minV != nil && maxV != nil && *minV > *maxV {
+		errs.add(minKey, fmt.Sprintf("%d-%d", *minV, *maxV), ErrInvalidRange)
+	}
+}
+
+// Limit return `lim` parameter
+func (params *UserSearchParams) Limit() int { return params.limit }
+
+// SetLimit set `lim` parameter
+func (params *UserSearchParams) SetLimit(limit int) {
+	if limit < minLimit || limit > maxLimit {
+		return
+	}
+	params.limit = limit
+}
+
+// ClearLimit clear `lim` parameter
+func (params *UserSearchParams) ClearLimit() { params.limit = 0 }
+
+// Start return `st` (offset) parameter
+func (params *UserSearchParams) Start() int { return params.offset }
+
+// SetStart set `st` (offset) parameter
+func (params *UserSearchParams) SetStart(start int) {
+	if start < minOffset || start > maxOffset {
+		return
+	}
+	params.offset = start
+}
+
+// ClearStart clear `st` (offset) parameter
+func (params *UserSearchParams) ClearStart() { params.offset = 0 }
+
+// Gzip return `gzip` compression level parameter
+func (params *UserSearchParams) Gzip() int { return params.gzip }
+
+// SetGzip set `gzip` compression level parameter, response is decompressed transparently
+func (params *UserSearchParams) SetGzip(level int) {
+	if level < minGzip || level > maxGzip {
+		return
+	}
+	params.gzip = level
+}
+
+// ClearGzip clear `gzip` parameter
+func (params *UserSearchParams) ClearGzip() { params.gzip = 0 }
+
+// Order return `order` parameter
+func (params *UserSearchParams) Order() UserOrderItem { return params.order }
+
+// SetOrder set `order` parameter
+func (params *UserSearchParams) SetOrder(order UserOrderItem) { params.order = order }
+
+// ClearOrder clear `order` param
+func (params *UserSearchParams) ClearOrder() { params.order = UserOrderItemNew }
+
+// Words return `word` parameter
+func (params *UserSearchParams) Words() []string { return params.words }
+
+// AddWords add search words, searched in user name and yomikata
+func (params *UserSearchParams) AddWords(words []string) {
+	params.words = appendUnique(params.words, words)
+}
+
+// ClearWords clear search words
+func (params *UserSearchParams) ClearWords() { params.words = nil }
+
+// NotWords return `notword` parameter
+func (params *UserSearchParams) NotWords() []string { return params.notWords }
+
+// AddNotWords add excluding words
+func (params *UserSearchParams) AddNotWords(words []string) {
+	params.notWords = appendUnique(params.notWords, words)
+}
+
+// ClearNotWords clear excluding words
+func (params *UserSearchParams) ClearNotWords() { params.notWords = nil }
+
+// UserIDs return `userid` parameter
+func (params *UserSearchParams) UserIDs() []int { return params.userIDs }
+
+// AddUserIDs add search user ids
+func (params *UserSearchParams) AddUserIDs(users []int) {
+	params.userIDs = appendUnique(params.userIDs, users)
+}
+
+// ClearUserIDs clear user ids
+func (params *UserSearchParams) ClearUserIDs() { params.userIDs = nil }
+
+// Name1st return `name1st` parameter
+func (params *UserSearchParams) Name1st() string { return params.name1st }
+
+// SetName1st set `name1st`, first kana of yomikata such as `ア`, or `その他`
+func (params *UserSearchParams) SetName1st(name1st string) { params.name1st = name1st }
+
+// ClearName1st clear `name1st` parameter
+func (params *UserSearchParams) ClearName1st() { params.name1st = "" }
+
+// MinNovel return `minnovel` parameter if set, or nil
+func (params *UserSearchParams) MinNovel() *int { return params.minNovel }
+
+// SetMinNovel set `minnovel` parameter
+func (params *UserSearchParams) SetMinNovel(n int) { params.minNovel = &n }
+
+// ClearMinNovel unset `minnovel` parameter
+func (params *UserSearchParams) ClearMinNovel() { params.minNovel = nil }
+
+// MaxNovel return `maxnovel` parameter if set, or nil
+func (params *UserSearchParams) MaxNovel() *int { return params.maxNovel }
+
+// SetMaxNovel set `maxnovel` parameter
+func (params *UserSearchParams) SetMaxNovel(n int) { params.maxNovel = &n }
+
+// ClearMaxNovel unset `maxnovel` parameter
+func (params *UserSearchParams) ClearMaxNovel() { params.maxNovel = nil }
+
+// MinReview return `minreview` parameter if set, or nil
+func (params *UserSearchParams) MinReview() *int { return params.minReview }
+
+// SetMinReview set `minreview` parameter
+func (params *UserSearchParams) SetMinReview(n int) { params.minReview = &n }
+
+// ClearMinReview unset `minreview` parameter
+func (params *UserSearchParams) ClearMinReview() { params.minReview = nil }
+
+// MaxReview return `maxreview` parameter if set, or nil
+func (params *UserSearchParams) MaxReview() *int { return params.maxReview }
+
+// SetMaxReview set `maxreview` parameter
+func (params *UserSearchParams) SetMaxReview(n int) { params.maxReview = &n }
+
+// ClearMaxReview unset `maxreview` parameter
+func (params *UserSearchParams) ClearMaxReview() { params.maxReview = nil }
+
+// appendUnique appends values not in list yet, keeping order
+func appendUnique[T comparable](list []T, values []T) []T {
+	seen := make(map[T]bool, len(list))
+	for _, v := range list {
+		seen[v] = true
+	}
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,93 @@
+package narrow
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUserSearchParams_ToURL(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(p *UserSearchParams)
+		want  string
+	}{
+		{"empty", func(p *UserSearchParams) {}, "https://api.syosetu.com/userapi/api/?out=json"},
+		{"words", func(p *UserSearchParams) {
+			p.AddWords([]string{"a", "b", "a"})
+			p.AddNotWords([]string{"c"})
+		}, "https://api.syosetu.com/userapi/api/?notword=c&out=json&word=a+b"},
+		{"userids", func(p *UserSearchParams) { p.AddUserIDs([]int{1, 2}) }, "https://api.syosetu.com/userapi/api/?out=json&userid=1-2"},
+		{"name1st", func(p *UserSearchParams) { p.SetName1st("ア") }, "https://api.syosetu.com/userapi/api/?name1st=%E3%82%A2&out=json"},
+		{"counts", func(p *UserSearchParams) {
+			p.SetMinNovel(1)
+			p.SetMaxNovel(10)
+			p.SetMinReview(0)
+		}, "https://api.syosetu.com/userapi/api/?maxnovel=10&minnovel=1&minreview=0&out=json"},
+		{"order and paging", func(p *UserSearchParams) {
+			p.SetOrder(UserOrderItemSumGlobalPoint)
+			p.SetLimit(20)
+			p.SetStart(21)
+		}, "https://api.syosetu.com/userapi/api/?lim=20&order=sumglobalpoint&out=json&st=21"},
+		{"cleared", func(p *UserSearchParams) {
+			p.SetMinNovel(1)
+			p.ClearMinNovel()
+			p.SetLimit(0)
+		}, "https://api.syosetu.com/userapi/api/?out=json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewUserSearchParams()
+			tt.setup(p)
+			u, err := p.ToURL()
+			if err != nil {
+				t.Fatalf("UserSearchParams.ToURL() error = %v", err)
+			}
+			if u.String() != tt.want {
+				t.Errorf("UserSearchParams.ToURL() = %v, want %v", u, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserSearchParams_Valid(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(p *UserSearchParams)
+		want  error
+	}{
+		{"novel range", func(p *UserSearchParams) { p.SetMinNovel(5); p.SetMaxNovel(1) }, ErrInvalidRange},
+		{"negative review", func(p *UserSearchParams) { p.SetMinReview(-1) }, ErrOutOfRange},
+		{"name1st", func(p *UserSearchParams) { p.SetName1st("アイ") }, ErrUnknownValue},
+		{"name1st not kana", func(p *UserSearchParams) { p.SetName1st("a") }, ErrUnknownValue},
+		{"name1st kanji", func(p *UserSearchParams) { p.SetName1st("漢") }, ErrUnknownValue},
+		{"name1st hiragana", func(p *UserSearchParams) { p.SetName1st("あ") }, ErrUnknownValue},
+		{"order", func(p *UserSearchParams) { p.SetOrder(UserOrderItem(100)) }, ErrUnknownValue},
+		{"word conflict", func(p *UserSearchParams) {
+			p.AddWords([]string{"a"})
+			p.AddNotWords([]string{"a"})
+		}, ErrConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewUserSearchParams()
+			tt.setup(p)
+			ok, err := p.Valid()
+			if ok || !errors.Is(err, tt.want) {
+				t.Errorf("UserSearchParams.Valid() = %v, %v, want %v", ok, err, tt.want)
+			}
+			if u, _ := p.ToURL(); u != nil {
+				t.Errorf("UserSearchParams.ToURL() = %v, want nil", u)
+			}
+		})
+	}
+	if ok, err := NewUserSearchParams().Valid(); !ok || err != nil {
+		t.Errorf("UserSearchParams.Valid() = %v, %v, want true, nil", ok, err)
+	}
+	for _, name1st := range []string{"ア", "ワ", name1stOther} {
+		p := NewUserSearchParams()
+		p.SetName1st(name1st)
+		if ok, err := p.Valid(); !ok || err != nil {
+			t.Errorf("UserSearchParams.Valid() with name1st %q = %v, %v, want true, nil", name1st, ok, err)
+		}
+	}
+}